@@ -3,30 +3,37 @@ package main
 import (
 	"backend/common/config"
 	"backend/common/database"
-	"backend/common/ollama"
+	"backend/common/embedding"
 	"backend/common/qdrant"
 	"backend/generate/psql"
+	chunkProcedure "backend/procedure/chunk"
+	dedupProcedure "backend/procedure/dedup"
 	"backend/type/common"
+	"backend/util/chunker"
+	"backend/util/dedup"
 	"context"
 	"embed"
+	"encoding/json"
 	"fmt"
 	"github.com/bsthun/gut"
 	"github.com/google/uuid"
-	"github.com/ollama/ollama/api"
 	qd "github.com/qdrant/go-client/qdrant"
-	"github.com/tmc/langchaingo/textsplitter"
 	"go.uber.org/fx"
 	"strconv"
+	"strings"
 	"time"
 )
 
 var embedMigrations embed.FS
 
 type Embedder struct {
-	config       *config.Config
-	database     common.Database
-	qdrantClient *qd.Client
-	ollamaClient *api.Client
+	config         *config.Config
+	database       common.Database
+	qdrantClient   *qd.Client
+	embedder       embedding.Embedder
+	chunkers       map[string]*chunker.Chunker
+	chunkProcedure chunkProcedure.Server
+	dedupProcedure dedupProcedure.Server
 }
 
 func main() {
@@ -38,7 +45,9 @@ func main() {
 			config.Init,
 			database.Init,
 			qdrant.Init,
-			ollama.Init,
+			embedding.Init,
+			chunkProcedure.Serve,
+			dedupProcedure.Serve,
 		),
 		fx.Invoke(
 			invoke,
@@ -51,14 +60,19 @@ func invoke(
 	config *config.Config,
 	db common.Database,
 	qdrantClient *qd.Client,
-	ollamaClient *api.Client,
+	provider embedding.Embedder,
+	chunkService chunkProcedure.Server,
+	dedupService dedupProcedure.Server,
 ) {
 	// * create embedder instance
 	embedder := &Embedder{
-		config:       config,
-		database:     db,
-		qdrantClient: qdrantClient,
-		ollamaClient: ollamaClient,
+		config:         config,
+		database:       db,
+		qdrantClient:   qdrantClient,
+		embedder:       provider,
+		chunkers:       make(map[string]*chunker.Chunker),
+		chunkProcedure: chunkService,
+		dedupProcedure: dedupService,
 	}
 
 	embedder.processCompletedTasks()
@@ -114,32 +128,78 @@ func (r *Embedder) embedTask(task *psql.Task) {
 	}
 
 	if len(searchResp.Result) > 0 {
+		// * backfill category payload used by category scoped dedup
+		if _, err := r.qdrantClient.SetPayload(context.Background(), &qd.SetPayloadPoints{
+			CollectionName: *r.config.QdrantCollection,
+			Payload: map[string]*qd.Value{
+				"categoryId": {
+					Kind: &qd.Value_StringValue{
+						StringValue: strconv.FormatUint(*task.CategoryId, 10),
+					},
+				},
+			},
+			PointsSelector: &qd.PointsSelector{
+				PointsSelectorOneOf: &qd.PointsSelector_Filter{
+					Filter: &qd.Filter{
+						Must: []*qd.Condition{
+							{
+								ConditionOneOf: &qd.Condition_Field{
+									Field: &qd.FieldCondition{
+										Key: "taskId",
+										Match: &qd.Match{
+											MatchValue: &qd.Match_Keyword{
+												Keyword: strconv.FormatUint(*task.Id, 10),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}); err != nil {
+			gut.Fatal("failed to backfill category payload", fmt.Errorf("task %d: %v", *task.Id, err))
+			return
+		}
+
 		gut.Debug("task %d already has embeddings, skipping", *task.Id)
 		return
 	}
 
 	gut.Debug("embedding task %d", *task.Id)
 
+	// * reuse policy recorded at processing, resolve and record only for tasks processed before policy recording
+	policy := dedup.Recorded(task)
+	if policy == nil {
+		var er *gut.ErrorInstance
+		policy, er = r.dedupProcedure.DedupResolve(context.Background(), r.database.P(), task)
+		if er != nil {
+			gut.Fatal("failed to resolve dedup policy", fmt.Errorf("task %d: %v", *task.Id, er))
+			return
+		}
+	}
+
+	// * resolve chunker of recorded strategy
+	chunker, err := r.resolveChunker(task)
+	if err != nil {
+		gut.Fatal("failed to resolve chunker", fmt.Errorf("task %d: %v", *task.Id, err))
+		return
+	}
+
 	// * split content to chunks
-	splitter := textsplitter.NewRecursiveCharacter(
-		textsplitter.WithChunkSize(65535),
-		textsplitter.WithChunkOverlap(64),
-		textsplitter.WithSeparators([]string{
-			"\n\n", // * paragraphs first
-			"\n",   // * then newlines
-			". ",   // * then sentences
-			", ",   // * then commas
-			" ",    // * then spaces
-			"",     // * then chars
-		}),
-	)
-
-	chunks, err := splitter.SplitText(*task.Content)
+	chunks, err := chunker.Chunks(*task.Content)
 	if err != nil {
 		gut.Fatal("failed to split task content into chunks", fmt.Errorf("task %d: %v", *task.Id, err))
 		return
 	}
 
+	// * clear previously recorded chunks
+	if err := r.database.P().TaskChunkDeleteByTaskId(context.Background(), task.Id); err != nil {
+		gut.Fatal("failed to clear task chunks", fmt.Errorf("task %d: %v", *task.Id, err))
+		return
+	}
+
 	gut.Debug("task %d split into %d chunks", *task.Id, len(chunks))
 
 	duplicateCount := 0
@@ -148,16 +208,9 @@ func (r *Embedder) embedTask(task *psql.Task) {
 	for i, chunk := range chunks {
 		// * get embedding
 		embeddingAttempt := 0
-		var embeddingResp *api.EmbedResponse
 	embeddingAttempt:
 		embeddingAttempt++
-		embeddingResp, err := r.ollamaClient.Embed(context.Background(), &api.EmbedRequest{
-			Model:     *r.config.OllamaEmbeddingModel,
-			Input:     chunk,
-			KeepAlive: nil,
-			Truncate:  nil,
-			Options:   nil,
-		})
+		embeddings, err := r.embedder.Embed(context.Background(), []string{chunk.Text})
 		if err != nil {
 			if embeddingAttempt < 3 {
 				time.Sleep(2 * time.Second)
@@ -170,44 +223,15 @@ func (r *Embedder) embedTask(task *psql.Task) {
 		// * search in qdrant for similarity
 		searchResp, err := r.qdrantClient.GetPointsClient().Search(context.Background(), &qd.SearchPoints{
 			CollectionName: *r.config.QdrantCollection,
-			Vector:         embeddingResp.Embeddings[0],
+			Vector:         embeddings[0],
 			Limit:          uint64(1),
-			ScoreThreshold: gut.Ptr(float32(1)),
+			ScoreThreshold: gut.Ptr(float32(*policy.Threshold)),
 			WithPayload: &qd.WithPayloadSelector{
 				SelectorOptions: &qd.WithPayloadSelector_Enable{
 					Enable: true,
 				},
 			},
-			Filter: &qd.Filter{
-				Must: []*qd.Condition{
-					{
-						ConditionOneOf: &qd.Condition_Field{
-							Field: &qd.FieldCondition{
-								Key: "type",
-								Match: &qd.Match{
-									MatchValue: &qd.Match_Keyword{
-										Keyword: *task.Type,
-									},
-								},
-							},
-						},
-					},
-				},
-				MustNot: []*qd.Condition{
-					{
-						ConditionOneOf: &qd.Condition_Field{
-							Field: &qd.FieldCondition{
-								Key: "taskId",
-								Match: &qd.Match{
-									MatchValue: &qd.Match_Keyword{
-										Keyword: strconv.FormatUint(*task.Id, 10),
-									},
-								},
-							},
-						},
-					},
-				},
-			},
+			Filter: policy.Filter(*task.Id, *task.Type, *task.CategoryId),
 		})
 		if err != nil {
 			gut.Fatal("failed to search in qdrant", fmt.Errorf("task %d chunk %d: %v", *task.Id, i, err))
@@ -215,7 +239,10 @@ func (r *Embedder) embedTask(task *psql.Task) {
 		}
 
 		// * check if duplicate found
+		var match *qd.ScoredPoint
 		if len(searchResp.Result) > 0 {
+			match = searchResp.Result[0]
+
 			// * extract duplicate taskId
 			duplicateTaskId, err := strconv.ParseUint(searchResp.Result[0].Payload["taskId"].GetStringValue(), 10, 64)
 			if err != nil {
@@ -240,7 +267,7 @@ func (r *Embedder) embedTask(task *psql.Task) {
 			Vectors: &qd.Vectors{
 				VectorsOptions: &qd.Vectors_Vector{
 					Vector: &qd.Vector{
-						Data: embeddingResp.Embeddings[0],
+						Data: embeddings[0],
 					},
 				},
 			},
@@ -260,6 +287,11 @@ func (r *Embedder) embedTask(task *psql.Task) {
 						StringValue: *task.Type,
 					},
 				},
+				"categoryId": {
+					Kind: &qd.Value_StringValue{
+						StringValue: strconv.FormatUint(*task.CategoryId, 10),
+					},
+				},
 			},
 		}
 
@@ -273,7 +305,58 @@ func (r *Embedder) embedTask(task *psql.Task) {
 			gut.Fatal("failed to upsert point to qdrant", fmt.Errorf("task %d chunk %d: %v", *task.Id, i, err))
 			return
 		}
+
+		// * record chunk with its point and duplicate match
+		if err := r.chunkProcedure.ChunkSave(context.Background(), r.database.P(), task.Id, chunk, &pointId, match); err != nil {
+			gut.Fatal("failed to save chunk", fmt.Errorf("task %d chunk %d: %v", *task.Id, i, err))
+			return
+		}
+	}
+
+	// * report duplicate under policy, completed tasks are kept as is
+	if policy.Duplicate(duplicateCount, len(chunks)) {
+		gut.Debug("task %d is duplicate of %s", *task.Id, strings.Join(duplicateTaskIds, ", "))
 	}
 
 	gut.Debug("task %d embedded successfully with %d chunks", *task.Id, len(chunks))
 }
+
+func (r *Embedder) resolveChunker(task *psql.Task) (*chunker.Chunker, error) {
+	// * parse recorded strategy
+	strategy := new(chunker.Strategy)
+	if err := json.Unmarshal(task.Chunking, strategy); err != nil {
+		return nil, err
+	}
+
+	// * record configured strategy for task chunked before strategy recording
+	if strategy.Name == nil {
+		strategy = r.config.Chunker
+		chunking, err := json.Marshal(strategy)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.database.P().TaskUpdateChunking(context.Background(), &psql.TaskUpdateChunkingParams{
+			Id:       task.Id,
+			Chunking: chunking,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	// * reuse chunker of same strategy
+	key, err := json.Marshal(strategy)
+	if err != nil {
+		return nil, err
+	}
+	if chunker, ok := r.chunkers[string(key)]; ok {
+		return chunker, nil
+	}
+
+	chunker, err := chunker.New(strategy)
+	if err != nil {
+		return nil, err
+	}
+	r.chunkers[string(key)] = chunker
+
+	return chunker, nil
+}
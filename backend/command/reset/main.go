@@ -64,13 +64,13 @@ func (r *Resetter) reset() {
 		}
 	}()
 
-	// * reset failed tasks to queuing
+	// * reset failed and low quality tasks to queuing
 	tasks, err := querier.TaskResetFailed(ctx)
 	if err != nil {
 		gut.Fatal("failed to reset failed tasks", err)
 	}
 
-	gut.Debug("reset %d failed tasks", len(tasks))
+	gut.Debug("reset %d failed and low quality tasks", len(tasks))
 
 	// * delete points from qdrant for each task
 	for _, task := range tasks {
@@ -3,24 +3,15 @@ package main
 import (
 	"backend/common/config"
 	oai "backend/common/openai"
+	"backend/common/pdf"
+	"backend/util/resolver"
 	"bytes"
 	"context"
 	"database/sql"
 	"embed"
-	"encoding/base64"
-	"fmt"
-	"image/jpeg"
-	"io"
-	"net/http"
-	"os"
-	"path/filepath"
-	"regexp"
-	"strings"
-	"sync"
 	"time"
 
 	"github.com/bsthun/gut"
-	"github.com/gen2brain/go-fitz"
 	_ "github.com/lib/pq"
 	"github.com/openai/openai-go"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
@@ -32,7 +23,7 @@ var embedMigrations embed.FS
 type OcrReviser struct {
 	config   *config.Config
 	database *sql.DB
-	openai   *openai.Client
+	pdf      *pdf.Reader
 }
 
 type TaskRevise struct {
@@ -89,7 +80,7 @@ func invoke(
 	reviser := &OcrReviser{
 		config:   config,
 		database: db,
-		openai:   openai,
+		pdf:      pdf.New(config, openai),
 	}
 
 	reviser.reviseOcr()
@@ -146,31 +137,14 @@ func (r *OcrReviser) reviseOcr() {
 	for _, task := range tasks {
 		gut.Debug("processing task %d with source %s", *task.Id, *task.Source)
 
-		var pdfData []byte
-		var err error
+		// * resolve share link to direct download url
+		source, _ := resolver.Resolve(*task.Source)
 
-		// * check if url is google drive
-		if r.isGoogleDriveUrl(*task.Source) {
-			// * extract file id from google drive url
-			fileId := r.extractFileId(*task.Source)
-			if fileId == "" {
-				gut.Debug("task %d: could not extract file id from %s", *task.Id, *task.Source)
-				continue
-			}
-
-			// * download pdf from google drive
-			pdfData, err = r.downloadFromGoogleDrive(fileId)
-			if err != nil {
-				gut.Debug("task %d: failed to download pdf from google drive: %v", *task.Id, err)
-				continue
-			}
-		} else {
-			// * download pdf from direct url
-			pdfData, err = r.downloadDirectUrl(*task.Source)
-			if err != nil {
-				gut.Debug("task %d: failed to download pdf from direct url: %v", *task.Id, err)
-				continue
-			}
+		// * download pdf from resolved url
+		pdfData, err := r.pdf.Fetch(ctx, source)
+		if err != nil {
+			gut.Debug("task %d: failed to download pdf from %s: %v", *task.Id, source, err)
+			continue
 		}
 
 		// * validate pdf first by attempting to decode it
@@ -180,8 +154,8 @@ func (r *OcrReviser) reviseOcr() {
 			continue
 		}
 
-		// * extract text from pdf
-		extractedText, err := r.extractTextFromPdf(pdfData)
+		// * extract text layer of pdf, escalating pages to ocr
+		document, err := r.pdf.Extract(ctx, pdfData)
 		if err != nil {
 			gut.Debug("task %d: failed to extract text from pdf: %v", *task.Id, err)
 			continue
@@ -192,110 +166,19 @@ func (r *OcrReviser) reviseOcr() {
 			UPDATE _task_revises2
 			SET content = $1
 			WHERE id = $2
-		`, extractedText, task.Id)
+		`, document.Text, task.Id)
 		if err != nil {
 			gut.Debug("task %d: failed to update content: %v", *task.Id, err)
 			continue
 		}
 
-		gut.Debug("task %d: successfully extracted and updated content (%d chars)", *task.Id, len(extractedText))
+		gut.Debug("task %d: successfully extracted and updated content (%d chars, %d ocr pages)", *task.Id, len(document.Text), len(document.OcrPages))
 		processedCount++
 	}
 
 	gut.Debug("processed %d tasks", processedCount)
 }
 
-func (r *OcrReviser) isGoogleDriveUrl(url string) bool {
-	// * check if url contains google drive domain
-	return strings.Contains(url, "drive.google.com") || strings.Contains(url, "docs.google.com")
-}
-
-func (r *OcrReviser) downloadDirectUrl(url string) ([]byte, error) {
-	// * create http client with timeout
-	client := &http.Client{
-		Timeout: 60 * time.Second,
-	}
-
-	// * make request
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, gut.Err(false, "failed to download file", err)
-	}
-	defer resp.Body.Close()
-
-	// * check response status
-	if resp.StatusCode != http.StatusOK {
-		return nil, gut.Err(false, fmt.Sprintf("failed to download file: status %d", resp.StatusCode))
-	}
-
-	// * read response body
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, gut.Err(false, "failed to read response body", err)
-	}
-
-	return data, nil
-}
-
-func (r *OcrReviser) extractFileId(url string) string {
-	// * regex patterns for different google drive url formats
-	patterns := []string{
-		`/file/d/([a-zA-Z0-9_-]+)`,
-		`id=([a-zA-Z0-9_-]+)`,
-		`/d/([a-zA-Z0-9_-]+)`,
-	}
-
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		matches := re.FindStringSubmatch(url)
-		if len(matches) > 1 {
-			return matches[1]
-		}
-	}
-
-	return ""
-}
-
-func (r *OcrReviser) downloadFromGoogleDrive(fileId string) ([]byte, error) {
-	// * construct direct download url
-	downloadUrl := fmt.Sprintf("https://drive.google.com/uc?export=download&id=%s", fileId)
-
-	// * create http client with timeout
-	client := &http.Client{
-		Timeout: 60 * time.Second,
-	}
-
-	// * make request
-	resp, err := client.Get(downloadUrl)
-	if err != nil {
-		return nil, gut.Err(false, "failed to download file", err)
-	}
-	defer resp.Body.Close()
-
-	// * check response status
-	if resp.StatusCode != http.StatusOK {
-		// * try alternative download url
-		downloadUrl = fmt.Sprintf("https://drive.google.com/u/0/uc?id=%s&export=download", fileId)
-		resp, err = client.Get(downloadUrl)
-		if err != nil {
-			return nil, gut.Err(false, "failed to download file with alternative url", err)
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			return nil, gut.Err(false, fmt.Sprintf("failed to download file: status %d", resp.StatusCode))
-		}
-	}
-
-	// * read response body
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, gut.Err(false, "failed to read response body", err)
-	}
-
-	return data, nil
-}
-
 func (r *OcrReviser) validatePdf(pdfData []byte) error {
 	// * create byte reader from pdf data
 	reader := bytes.NewReader(pdfData)
@@ -308,211 +191,3 @@ func (r *OcrReviser) validatePdf(pdfData []byte) error {
 
 	return nil
 }
-
-func (r *OcrReviser) extractTextFromPdf(pdfData []byte) (string, error) {
-	// * create temp directory if not exists
-	tempDir := ".local/temp"
-	err := os.MkdirAll(tempDir, os.ModePerm)
-	if err != nil {
-		return "", gut.Err(false, "failed to create temp directory", err)
-	}
-
-	// * create temp file for pdf
-	tempFile := filepath.Join(tempDir, fmt.Sprintf("temp_%d.pdf", time.Now().UnixNano()))
-	err = os.WriteFile(tempFile, pdfData, 0644)
-	if err != nil {
-		return "", gut.Err(false, "failed to write temp pdf file", err)
-	}
-	defer os.Remove(tempFile)
-
-	// * open pdf with fitz for rendering pages as images
-	doc, err := fitz.New(tempFile)
-	if err != nil {
-		return "", gut.Err(false, "failed to open pdf with fitz", err)
-	}
-	defer doc.Close()
-
-	// * prepare for concurrent processing
-	totalPages := doc.NumPage()
-	type pageResult struct {
-		pageNo int
-		text   string
-		err    error
-	}
-
-	// * create channel for results and semaphore for concurrency control
-	resultChan := make(chan pageResult, totalPages)
-	semaphore := make(chan struct{}, 48)
-
-	// * waitgroup to track all goroutines
-	var wg sync.WaitGroup
-
-	// * spawn goroutines for each page
-	for pageNo := 0; pageNo < totalPages; pageNo++ {
-		wg.Add(1)
-		go func(pageNum int) {
-			defer wg.Done()
-
-			defer func() {
-				if r := recover(); r != nil {
-					gut.Debug("recovered from panic in fitz: %v", r)
-				}
-			}()
-
-			// * acquire semaphore slot
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			// * render page as image
-			img, err := doc.Image(pageNum)
-			if err != nil {
-				resultChan <- pageResult{
-					pageNo: pageNum,
-					text:   "",
-					err:    fmt.Errorf("failed to render page %d as image: %v", pageNum+1, err),
-				}
-				return
-			}
-
-			// * create in-memory buffer for image
-			imgBuffer := new(bytes.Buffer)
-
-			// * encode image to jpeg in memory
-			err = jpeg.Encode(imgBuffer, img, &jpeg.Options{Quality: 95})
-			if err != nil {
-				resultChan <- pageResult{
-					pageNo: pageNum,
-					text:   "",
-					err:    fmt.Errorf("failed to encode page image for page %d: %v", pageNum+1, err),
-				}
-				return
-			}
-
-			// * get image data from buffer
-			imageData := imgBuffer.Bytes()
-
-			// * encode image to base64
-			base64Image := base64.StdEncoding.EncodeToString(imageData)
-
-			// * extract text using openai
-			pageText, err := r.extractTextWithOpenAI(base64Image, pageNum+1)
-			if err != nil {
-				resultChan <- pageResult{
-					pageNo: pageNum,
-					text:   "",
-					err:    fmt.Errorf("failed to extract text with openai for page %d: %v", pageNum+1, err),
-				}
-				return
-			}
-
-			println(pageText)
-
-			// * send successful result
-			resultChan <- pageResult{
-				pageNo: pageNum,
-				text:   pageText,
-				err:    nil,
-			}
-		}(pageNo)
-	}
-
-	// * close result channel when all goroutines complete
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
-
-	// * collect results in order
-	pageTexts := make(map[int]string)
-	for result := range resultChan {
-		if result.err != nil {
-			gut.Debug("%v", result.err)
-			continue
-		}
-		pageTexts[result.pageNo] = result.text
-	}
-
-	// * build final text in correct page order
-	var textBuilder strings.Builder
-	for pageNo := 0; pageNo < totalPages; pageNo++ {
-		text, exists := pageTexts[pageNo]
-		if !exists {
-			continue
-		}
-
-		// * add page separator if not first page
-		if pageNo > 0 && textBuilder.Len() > 0 {
-			textBuilder.WriteString("\n\n--- Page ")
-			textBuilder.WriteString(fmt.Sprintf("%d", pageNo+1))
-			textBuilder.WriteString(" ---\n\n")
-		}
-
-		// * add extracted text
-		textBuilder.WriteString(text)
-	}
-
-	return textBuilder.String(), nil
-}
-
-func (r *OcrReviser) extractTextWithOpenAI(base64Image string, pageNo int) (string, error) {
-	// * create openai messages for text extraction
-	var messages []openai.ChatCompletionMessageParamUnion
-
-	// * add system message
-	systemContent := "Output text content from this image, detects only English and Thai character, keeping original content and do not translate or modify the content. Remove and clean unnecessary spaces, line breaks, emojis, symbols, and characters. Remove line without meaningful context. Replace or merge any repeating dot or dashes into only '...'. This must revise arrangement or combine chunking of context to be meaningful content, ignore image or any non-text content, simplified markdown without any extra line break or separators. Can use extra formatting only '*' for bullet, markdown table, latex block for equations, otherwise will fallback to plain text. If no text, respond with '-'."
-	messages = append(messages, openai.SystemMessage(systemContent))
-
-	// * add user content parts
-	var userContentParts []openai.ChatCompletionContentPartUnionParam
-	userContentParts = append(userContentParts, openai.TextContentPart(systemContent))
-	userContentParts = append(userContentParts, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
-		URL: fmt.Sprintf("data:image/jpeg;base64,%s", base64Image),
-	}))
-
-	// * add user message with content parts
-	messages = append(messages, openai.ChatCompletionMessageParamUnion{
-		OfUser: &openai.ChatCompletionUserMessageParam{
-			Role: "user",
-			Content: openai.ChatCompletionUserMessageParamContentUnion{
-				OfArrayOfContentParts: userContentParts,
-			},
-		},
-	})
-
-	// * prepare chat completion request params
-	chatParams := openai.ChatCompletionNewParams{
-		Messages:         messages,
-		Model:            *r.config.OpenaiModel,
-		MaxTokens:        openai.Int(2048),
-		Temperature:      openai.Float(0.1),
-		TopP:             openai.Float(1),
-		FrequencyPenalty: openai.Float(0.5),
-		PresencePenalty:  openai.Float(0.2),
-	}
-
-	// * call openai api with retry
-	ctx := context.Background()
-	maxRetries := 3
-	var chatCompletion *openai.ChatCompletion
-	var err error
-
-	for i := 0; i < maxRetries; i++ {
-		chatCompletion, err = r.openai.Chat.Completions.New(ctx, chatParams)
-		if err == nil && len(chatCompletion.Choices) > 0 {
-			break
-		}
-		if i < maxRetries-1 {
-			gut.Debug("retry %d for page %d due to error: %v", i+1, pageNo, err)
-			time.Sleep(2 * time.Second)
-		}
-	}
-
-	if err != nil {
-		return "", gut.Err(false, fmt.Sprintf("failed to extract text for page %d after %d retries", pageNo, maxRetries), err)
-	}
-
-	// * get extracted text
-	extractedText := chatCompletion.Choices[0].Message.Content
-
-	return extractedText, nil
-}
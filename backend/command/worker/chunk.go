@@ -0,0 +1,15 @@
+package main
+
+import (
+	"backend/generate/psql"
+	"context"
+)
+
+func (r *Worker) saveDuplicate(ctx context.Context, taskId *uint64, duplicateTaskId *uint64, chunkNo *int32, score float64) error {
+	return r.database.P().TaskDuplicateCreate(ctx, &psql.TaskDuplicateCreateParams{
+		TaskId:            taskId,
+		DuplicateOfTaskId: duplicateTaskId,
+		ChunkNo:           chunkNo,
+		Score:             &score,
+	})
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"backend/generate/psql"
+	"backend/type/common"
+	"backend/util/politeness"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var ErrDeficitSpent = errors.New("deficit of flow is spent")
+
+var ErrFlowHeld = errors.New("tasks of flow are held")
+
+func (r *Worker) claimTask(ctx context.Context) (psql.Task, error) {
+	skipped := make([]uint64, 0)
+	for attempt := 0; attempt < 8; attempt++ {
+		task, queue, err := r.claimFlow(ctx, skipped)
+		if errors.Is(err, ErrDeficitSpent) {
+			// * start new round of priority once no flow has deficit left
+			if err := r.database.P().TaskQueueReplenish(ctx, queue.Priority); err != nil {
+				return psql.Task{}, err
+			}
+			continue
+		}
+		if errors.Is(err, ErrFlowHeld) {
+			skipped = append(skipped, *queue.UserId)
+			continue
+		}
+		if err != nil {
+			return psql.Task{}, err
+		}
+
+		return task, nil
+	}
+
+	return psql.Task{}, sql.ErrNoRows
+}
+
+func (r *Worker) claimFlow(ctx context.Context, skipped []uint64) (psql.Task, psql.TaskQueue, error) {
+	// * pick, claim and charge in single transaction, holding flow row against concurrent claims
+	tx, querier := r.database.Ptx(ctx, nil)
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// * pick flow of highest priority, preferring flows with deficit left in current round
+	queue, err := querier.TaskQueuePick(ctx, skipped)
+	if err != nil {
+		return psql.Task{}, queue, err
+	}
+	if *queue.Deficit <= 0 {
+		return psql.Task{}, queue, ErrDeficitSpent
+	}
+
+	// * lock oldest task of flow within host limits
+	candidate, err := querier.TaskClaimCandidate(ctx, &psql.TaskClaimCandidateParams{
+		UserId:          queue.UserId,
+		Priority:        queue.Priority,
+		HostLimits:      politeness.Overrides(r.config.WorkerHosts),
+		HostConcurrency: r.config.WorkerHostConcurrency,
+		HostRate:        r.config.WorkerHostRate,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return psql.Task{}, queue, r.rotateFlow(ctx, tx, querier, queue)
+	}
+	if err != nil {
+		return psql.Task{}, queue, err
+	}
+
+	// * serialize claims of same host, so host limits are checked against committed claims
+	if candidate.SourceHost != nil {
+		if err := querier.TaskHostLock(ctx, candidate.SourceHost); err != nil {
+			return psql.Task{}, queue, err
+		}
+	}
+
+	// * claim task once host limits still hold
+	task, err := querier.TaskClaimById(ctx, &psql.TaskClaimByIdParams{
+		ClaimedBy:       &r.id,
+		LeaseSeconds:    r.leaseSeconds(),
+		Id:              candidate.Id,
+		HostLimits:      politeness.Overrides(r.config.WorkerHosts),
+		HostConcurrency: r.config.WorkerHostConcurrency,
+		HostRate:        r.config.WorkerHostRate,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return psql.Task{}, queue, r.rotateFlow(ctx, tx, querier, queue)
+	}
+	if err != nil {
+		return psql.Task{}, queue, err
+	}
+
+	// * charge claimed task against deficit of flow, weighted by task category
+	if err := querier.TaskQueueCharge(ctx, &psql.TaskQueueChargeParams{
+		CategoryId: task.CategoryId,
+		UserId:     queue.UserId,
+		Priority:   queue.Priority,
+	}); err != nil {
+		return psql.Task{}, queue, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return psql.Task{}, queue, err
+	}
+
+	return task, queue, nil
+}
+
+func (r *Worker) rotateFlow(ctx context.Context, tx common.DatabaseTx, querier psql.PQuerier, queue psql.TaskQueue) error {
+	// * tasks of flow are held by host limits or other workers, move flow to end of round
+	if err := querier.TaskQueueRotate(ctx, &psql.TaskQueueRotateParams{
+		UserId:   queue.UserId,
+		Priority: queue.Priority,
+	}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return ErrFlowHeld
+}
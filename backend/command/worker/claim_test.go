@@ -0,0 +1,87 @@
+package main
+
+import (
+	"backend/common/config"
+	"backend/generate/psql"
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bsthun/gut"
+)
+
+func TestClaimTask(t *testing.T) {
+	flow := func(userId uint64, deficit float64) psql.TaskQueue {
+		return psql.TaskQueue{UserId: gut.Ptr(userId), Priority: gut.Ptr(int32(0)), Queued: gut.Ptr(int32(1)), Deficit: gut.Ptr(deficit)}
+	}
+	tests := []struct {
+		name       string
+		picks      []psql.TaskQueue
+		candidates map[uint64]error
+		claimed    *uint64
+		err        error
+		calls      []string
+	}{
+		{
+			name:    "claim and charge in one transaction",
+			picks:   []psql.TaskQueue{flow(1, 1)},
+			claimed: gut.Ptr(uint64(1)),
+			calls:   []string{"pick", "candidate", "claim", "charge", "commit"},
+		},
+		{
+			name:    "replenish spent round outside transaction",
+			picks:   []psql.TaskQueue{flow(1, 0), flow(1, 1)},
+			claimed: gut.Ptr(uint64(1)),
+			calls:   []string{"pick", "rollback", "replenish", "pick", "candidate", "claim", "charge", "commit"},
+		},
+		{
+			name:       "rotate held flow and skip it",
+			picks:      []psql.TaskQueue{flow(1, 1), flow(1, 1), flow(2, 1)},
+			candidates: map[uint64]error{1: sql.ErrNoRows},
+			claimed:    gut.Ptr(uint64(2)),
+			calls:      []string{"pick", "candidate", "rotate", "commit", "pick", "candidate", "claim", "charge", "commit"},
+		},
+		{
+			name:  "no queued flow",
+			picks: nil,
+			err:   sql.ErrNoRows,
+			calls: []string{"pick", "rollback"},
+		},
+		{
+			name:       "candidate error is not charged",
+			picks:      []psql.TaskQueue{flow(1, 1)},
+			candidates: map[uint64]error{1: errors.New("connection reset")},
+			err:        errors.New("connection reset"),
+			calls:      []string{"pick", "candidate", "rollback"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			querier := &fakeQuerier{picks: test.picks, candidates: test.candidates}
+			worker := &Worker{
+				id:       "worker",
+				config:   &config.Config{WorkerLeaseDuration: gut.Ptr(time.Minute)},
+				database: &fakeDatabase{querier: querier},
+			}
+
+			task, err := worker.claimTask(context.Background())
+			if test.err != nil {
+				if err == nil || err.Error() != test.err.Error() {
+					t.Fatalf("expected error %v, got %v", test.err, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if test.claimed != nil && (task.Id == nil || *task.Id != *test.claimed) {
+				t.Errorf("expected claimed task %d, got %v", *test.claimed, task.Id)
+			}
+			if !slices.Equal(querier.calls, test.calls) {
+				t.Errorf("expected calls %v, got %v", test.calls, querier.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"backend/generate/psql"
+	"context"
+
+	"github.com/bsthun/gut"
+)
+
+func (r *Worker) classify(ctx context.Context, task *psql.Task, content string) {
+	// * classification is advisory, errors do not fail task
+	categories, err := r.database.P().CategoryList(ctx)
+	if err != nil {
+		gut.Debug("failed to list categories for task %d: %v", *task.Id, err)
+		return
+	}
+
+	// * prompt chat model with opening text of content
+	runes := []rune(content)
+	if len(runes) > *r.config.WorkerClassifyTextLength {
+		runes = runes[:*r.config.WorkerClassifyTextLength]
+	}
+	suggestion, err := r.classifier.Classify(ctx, categories, string(runes))
+	if err != nil {
+		gut.Debug("failed to classify task %d: %v", *task.Id, err)
+		return
+	}
+
+	categoryId := suggestion.CategoryId
+	if err := r.database.P().TaskUpdateSuggestedCategory(ctx, &psql.TaskUpdateSuggestedCategoryParams{
+		Id:                  task.Id,
+		SuggestedCategoryId: categoryId,
+		SuggestedConfidence: &suggestion.Confidence,
+	}); err != nil {
+		gut.Debug("failed to update suggested category of task %d: %v", *task.Id, err)
+		return
+	}
+
+	// * recategorize confident suggestion, submitted category is kept for review
+	threshold := r.config.WorkerClassifyAutoThreshold
+	if threshold == nil || suggestion.Confidence < *threshold {
+		return
+	}
+	if task.CategoryId != nil && *task.CategoryId == *categoryId {
+		return
+	}
+	if err := r.database.P().TaskUpdateCategory(ctx, &psql.TaskUpdateCategoryParams{
+		Id:         task.Id,
+		CategoryId: categoryId,
+	}); err != nil {
+		gut.Debug("failed to recategorize task %d: %v", *task.Id, err)
+		return
+	}
+	task.CategoryId = categoryId
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"backend/generate/psql"
+	"backend/type/common"
+	"context"
+	"database/sql"
+	"sync"
+)
+
+type fakeDatabase struct {
+	querier *fakeQuerier
+}
+
+func (r *fakeDatabase) P() psql.PQuerier {
+	return r.querier
+}
+
+func (r *fakeDatabase) Ptx(context.Context, *sql.TxOptions) (common.DatabaseTx, psql.PQuerier) {
+	return &fakeTx{querier: r.querier}, r.querier
+}
+
+type fakeTx struct {
+	querier *fakeQuerier
+	done    bool
+}
+
+func (r *fakeTx) Commit() error {
+	if !r.done {
+		r.done = true
+		r.querier.record("commit")
+	}
+	return nil
+}
+
+func (r *fakeTx) Rollback() error {
+	if !r.done {
+		r.done = true
+		r.querier.record("rollback")
+	}
+	return nil
+}
+
+// * queries not overridden panic through nil embedded querier
+type fakeQuerier struct {
+	psql.PQuerier
+	mutex sync.Mutex
+	calls []string
+
+	picks      []psql.TaskQueue
+	candidates map[uint64]error
+	leaseErr   error
+	exact      *psql.Task
+	failed     []*psql.TaskUpdateFailedParams
+	duplicates []*psql.TaskDuplicateCreateParams
+}
+
+func (r *fakeQuerier) record(call string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *fakeQuerier) TaskQueuePick(_ context.Context, skipped []uint64) (psql.TaskQueue, error) {
+	r.record("pick")
+	for len(r.picks) > 0 {
+		queue := r.picks[0]
+		r.picks = r.picks[1:]
+		skip := false
+		for _, userId := range skipped {
+			skip = skip || userId == *queue.UserId
+		}
+		if !skip {
+			return queue, nil
+		}
+	}
+	return psql.TaskQueue{}, sql.ErrNoRows
+}
+
+func (r *fakeQuerier) TaskQueueReplenish(context.Context, *int32) error {
+	r.record("replenish")
+	return nil
+}
+
+func (r *fakeQuerier) TaskQueueRotate(context.Context, *psql.TaskQueueRotateParams) error {
+	r.record("rotate")
+	return nil
+}
+
+func (r *fakeQuerier) TaskQueueCharge(context.Context, *psql.TaskQueueChargeParams) error {
+	r.record("charge")
+	return nil
+}
+
+func (r *fakeQuerier) TaskClaimCandidate(_ context.Context, arg *psql.TaskClaimCandidateParams) (psql.TaskClaimCandidateRow, error) {
+	r.record("candidate")
+	if err := r.candidates[*arg.UserId]; err != nil {
+		return psql.TaskClaimCandidateRow{}, err
+	}
+	return psql.TaskClaimCandidateRow{Id: arg.UserId, SourceHost: nil}, nil
+}
+
+func (r *fakeQuerier) TaskClaimById(_ context.Context, arg *psql.TaskClaimByIdParams) (psql.Task, error) {
+	r.record("claim")
+	return psql.Task{Id: arg.Id}, nil
+}
+
+func (r *fakeQuerier) TaskLeaseExtend(context.Context, *psql.TaskLeaseExtendParams) (*uint64, error) {
+	r.record("extend")
+	return nil, r.leaseErr
+}
+
+func (r *fakeQuerier) TaskRequeue(context.Context, *psql.TaskRequeueParams) (int64, error) {
+	r.record("requeue")
+	return 1, nil
+}
+
+func (r *fakeQuerier) TaskDuplicateDeleteByTaskId(context.Context, *uint64) error {
+	r.record("duplicate delete")
+	return nil
+}
+
+func (r *fakeQuerier) TaskUpdateContentSha256(context.Context, *psql.TaskUpdateContentSha256Params) error {
+	r.record("content sha256")
+	return nil
+}
+
+func (r *fakeQuerier) TaskGetExactDuplicate(context.Context, *psql.TaskGetExactDuplicateParams) (psql.Task, error) {
+	r.record("exact duplicate")
+	if r.exact == nil {
+		return psql.Task{}, sql.ErrNoRows
+	}
+	return *r.exact, nil
+}
+
+func (r *fakeQuerier) TaskDuplicateCreate(_ context.Context, arg *psql.TaskDuplicateCreateParams) error {
+	r.record("duplicate create")
+	r.duplicates = append(r.duplicates, arg)
+	return nil
+}
+
+func (r *fakeQuerier) TaskUpdateFailed(_ context.Context, arg *psql.TaskUpdateFailedParams) (int64, error) {
+	r.record("failed")
+	r.failed = append(r.failed, arg)
+	return 0, nil
+}
+
+func (r *fakeQuerier) TaskUpdateMetadata(context.Context, *psql.TaskUpdateMetadataParams) error {
+	r.record("metadata")
+	return nil
+}
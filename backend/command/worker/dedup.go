@@ -0,0 +1,80 @@
+package main
+
+import (
+	"backend/generate/psql"
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/bsthun/gut"
+	qd "github.com/qdrant/go-client/qdrant"
+)
+
+func (r *Worker) takeover(ctx context.Context, job *Job) bool {
+	// * flush points of all chunks not matching ignored task
+	if err := r.upsert(ctx, job.stat, job.points); err != nil {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("qdrant upsert error: %v", err)),
+			Title:        job.title,
+			Content:      job.content,
+			TokenCount:   job.tokenCount,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+
+	_, err := r.qdrantClient.SetPayload(ctx, &qd.SetPayloadPoints{
+		CollectionName: *r.config.QdrantCollection,
+		Payload: map[string]*qd.Value{
+			"taskId": {
+				Kind: &qd.Value_StringValue{
+					StringValue: strconv.FormatUint(*job.task.Id, 10),
+				},
+			},
+		},
+		PointsSelector: &qd.PointsSelector{
+			PointsSelectorOneOf: &qd.PointsSelector_Filter{
+				Filter: &qd.Filter{
+					Must: []*qd.Condition{
+						{
+							ConditionOneOf: &qd.Condition_Field{
+								Field: &qd.FieldCondition{
+									Key: "taskId",
+									Match: &qd.Match{
+										MatchValue: &qd.Match_Keyword{
+											Keyword: strconv.FormatUint(*job.revisedTask.Id, 10),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("qdrant ignored deduplicate upsert error: %v", err)),
+			Title:        job.title,
+			Content:      job.content,
+			TokenCount:   job.tokenCount,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+
+	// * update task as completed
+	r.complete(&psql.TaskUpdateCompletedParams{
+		Id:            job.task.Id,
+		Title:         job.title,
+		Content:       job.content,
+		TokenCount:    job.tokenCount,
+		RevisedTaskId: job.revisedTask.Id,
+		ClaimedBy:     nil,
+	})
+
+	return true
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"backend/generate/psql"
+	"backend/util/dedup"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bsthun/gut"
+	"github.com/google/uuid"
+	qd "github.com/qdrant/go-client/qdrant"
+)
+
+func (r *Worker) embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	r.metric.EmbeddingBatchSize.Observe(float64(len(inputs)))
+
+	attempt := 0
+	for {
+		attempt++
+
+		// * get embeddings of all inputs in single request
+		embeddingCtx, cancel := context.WithTimeout(ctx, *r.config.WorkerEmbeddingTimeout)
+		embeddings, err := r.embedder.Embed(embeddingCtx, inputs)
+		cancel()
+		if err != nil {
+			if attempt < 3 && sleep(ctx, 2*time.Second) {
+				continue
+			}
+			return nil, err
+		}
+
+		return embeddings, nil
+	}
+}
+
+func (r *Worker) search(ctx context.Context, task *psql.Task, policy *dedup.Policy, vectors [][]float32) ([]*qd.BatchResult, error) {
+	// * construct search of each vector
+	searchPoints := make([]*qd.SearchPoints, 0, len(vectors))
+	for _, vector := range vectors {
+		searchPoints = append(searchPoints, &qd.SearchPoints{
+			CollectionName: *r.config.QdrantCollection,
+			Vector:         vector,
+			Limit:          uint64(1),
+			ScoreThreshold: gut.Ptr(float32(*policy.Threshold)),
+			WithPayload: &qd.WithPayloadSelector{
+				SelectorOptions: &qd.WithPayloadSelector_Enable{
+					Enable: true,
+				},
+			},
+			Filter: policy.Filter(*task.Id, *task.Type, *task.CategoryId),
+		})
+	}
+
+	// * search all vectors in single request
+	searchCtx, cancel := context.WithTimeout(ctx, *r.config.WorkerQdrantTimeout)
+	defer cancel()
+	searchResp, err := r.qdrantClient.GetPointsClient().SearchBatch(searchCtx, &qd.SearchBatchPoints{
+		CollectionName: *r.config.QdrantCollection,
+		SearchPoints:   searchPoints,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(searchResp.Result) != len(vectors) {
+		return nil, fmt.Errorf("expected %d search results, got %d", len(vectors), len(searchResp.Result))
+	}
+
+	return searchResp.Result, nil
+}
+
+func (r *Worker) point(task *psql.Task, chunkNo int, vector []float32) *qd.PointStruct {
+	return &qd.PointStruct{
+		Id: &qd.PointId{
+			PointIdOptions: &qd.PointId_Uuid{
+				Uuid: uuid.New().String(),
+			},
+		},
+		Vectors: &qd.Vectors{
+			VectorsOptions: &qd.Vectors_Vector{
+				Vector: &qd.Vector{
+					Data: vector,
+				},
+			},
+		},
+		Payload: map[string]*qd.Value{
+			"taskId": {
+				Kind: &qd.Value_StringValue{
+					StringValue: strconv.FormatUint(*task.Id, 10),
+				},
+			},
+			"chunkNo": {
+				Kind: &qd.Value_IntegerValue{
+					IntegerValue: int64(chunkNo),
+				},
+			},
+			"type": {
+				Kind: &qd.Value_StringValue{
+					StringValue: *task.Type,
+				},
+			},
+			"categoryId": {
+				Kind: &qd.Value_StringValue{
+					StringValue: strconv.FormatUint(*task.CategoryId, 10),
+				},
+			},
+		},
+	}
+}
+
+func (r *Worker) upsert(ctx context.Context, stat *Stat, points []*qd.PointStruct) error {
+	size := *r.config.WorkerUpsertBatchSize
+	for offset := 0; offset < len(points); offset += size {
+		// * upsert points in batch
+		upsertStart := time.Now()
+		upsertCtx, cancel := context.WithTimeout(ctx, *r.config.WorkerQdrantTimeout)
+		_, err := r.qdrantClient.Upsert(upsertCtx, &qd.UpsertPoints{
+			CollectionName: *r.config.QdrantCollection,
+			Points:         points[offset:min(offset+size, len(points))],
+		})
+		cancel()
+		stat.UpsertDurations = append(stat.UpsertDurations, gut.Ptr(time.Since(upsertStart)))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"backend/generate/psql"
+	"context"
+	"time"
+
+	"github.com/bsthun/gut"
+)
+
+func (r *Worker) saveEndpointStates() {
+	for _, state := range r.extractPool.States() {
+		if err := r.database.P().ExtractEndpointStateUpsert(context.Background(), &psql.ExtractEndpointStateUpsertParams{
+			WorkerId:            &r.id,
+			Base:                &state.Base,
+			State:               &state.State,
+			Capacity:            gut.Ptr(int32(state.Capacity)),
+			Inflight:            gut.Ptr(int32(state.Inflight)),
+			ConsecutiveFailures: gut.Ptr(int32(state.ConsecutiveFailures)),
+			SuccessCount:        &state.SuccessCount,
+			FailureCount:        &state.FailureCount,
+			Latency:             gut.Ptr(uint64(state.Latency.Milliseconds())),
+			OpenedAt:            state.OpenedAt,
+			LastError:           state.LastError,
+		}); err != nil {
+			gut.Debug("failed to save extract endpoint state %s: %v", state.Base, err)
+		}
+	}
+}
+
+func (r *Worker) pruneEndpointStates() {
+	// * drop states of exited workers, running workers refresh theirs every report
+	deleted, err := r.database.P().ExtractEndpointStateDeleteStale(context.Background(), gut.Ptr(time.Now().Add(-time.Hour)))
+	if err != nil {
+		gut.Debug("failed to prune stale extract endpoint states: %v", err)
+		return
+	}
+	if deleted > 0 {
+		gut.Debug("pruned %d stale extract endpoint states", deleted)
+	}
+}
+
+func (r *Worker) reportEndpointStates(ctx context.Context) {
+	r.pruneEndpointStates()
+	for ctx.Err() == nil {
+		r.saveEndpointStates()
+		sleep(ctx, 15*time.Second)
+	}
+
+	// * final snapshot on shutdown
+	r.saveEndpointStates()
+}
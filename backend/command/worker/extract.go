@@ -0,0 +1,41 @@
+package main
+
+import (
+	"backend/common/extractor"
+	"backend/common/pdf"
+	"backend/generate/psql"
+	"context"
+	"errors"
+
+	"github.com/bsthun/gut"
+)
+
+func (r *Worker) extract(ctx context.Context, task *psql.Task, source string) (*extractor.Result, error) {
+	// * read text layer of pdf documents in worker, escalating pages to ocr
+	if *task.Type == "doc" && r.pdf != nil {
+		data, err := r.pdf.Fetch(ctx, source)
+		if err == nil {
+			document, err := r.pdf.Extract(ctx, data)
+			if err != nil {
+				return nil, err
+			}
+			title := ""
+			if document.Metadata.Title != nil {
+				title = *document.Metadata.Title
+			}
+			return &extractor.Result{
+				Title:     title,
+				Text:      document.Text,
+				Extractor: extractor.ExtractorPdf,
+				OcrPages:  document.OcrPages,
+				Metadata:  document.Metadata,
+			}, nil
+		}
+		if !errors.Is(err, pdf.ErrNotPdf) {
+			gut.Debug("failed to fetch pdf of task %d: %v", *task.Id, err)
+		}
+	}
+
+	// * other documents are handled by extraction service on healthy endpoints
+	return r.extractor.Extract(ctx, *task.Type, r.extractPool, source)
+}
@@ -1,39 +1,18 @@
 package main
 
 import (
-	"sync"
+	"context"
+	"time"
 )
 
-type Pool[T any] struct {
-	objects chan T
-	mu      sync.Mutex
-}
-
-func NewPool[T any](items []T) *Pool[T] {
-	pool := &Pool[T]{
-		objects: make(chan T, len(items)*2),
-	}
+func sleep(ctx context.Context, duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
 
-	// * add all items to the pool
-	for _, item := range items {
-		pool.objects <- item
-		pool.objects <- item
-	}
-
-	return pool
-}
-
-func (p *Pool[T]) Get() T {
-	return <-p.objects
-}
-
-func (p *Pool[T]) Put(obj T) {
 	select {
-	case p.objects <- obj:
-	default:
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
 	}
 }
-
-func (p *Pool[T]) Size() int {
-	return len(p.objects)
-}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"backend/generate/psql"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/bsthun/gut"
+)
+
+func (r *Worker) leaseSeconds() *int32 {
+	return gut.Ptr(int32(r.config.WorkerLeaseDuration.Seconds()))
+}
+
+var ErrLeaseLost = errors.New("lease lost")
+
+func (r *Worker) heartbeat(taskId *uint64, cancel context.CancelCauseFunc) func() {
+	done := make(chan struct{})
+	ticker := time.NewTicker(*r.config.WorkerLeaseDuration / 3)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// * extend lease of claimed task
+				if _, err := r.database.P().TaskLeaseExtend(context.Background(), &psql.TaskLeaseExtendParams{
+					Id:           taskId,
+					ClaimedBy:    &r.id,
+					LeaseSeconds: r.leaseSeconds(),
+				}); errors.Is(err, sql.ErrNoRows) {
+					// * task was reaped or claimed by another worker, abort job
+					gut.Debug("lost lease of task %d, aborting job", *taskId)
+					cancel(ErrLeaseLost)
+					return
+				} else if err != nil {
+					gut.Debug("failed to extend lease of task %d: %v", *taskId, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
+
+func (r *Worker) reap() {
+	// * requeue tasks with expired lease
+	requeued, err := r.database.P().TaskRequeueExpired(context.Background(), r.config.WorkerMaxAttempt)
+	if err != nil {
+		gut.Debug("failed to requeue expired tasks: %v", err)
+		return
+	}
+
+	// * fail tasks with expired lease after max attempt
+	failed, err := r.database.P().TaskFailExpired(context.Background(), r.config.WorkerMaxAttempt)
+	if err != nil {
+		gut.Debug("failed to fail expired tasks: %v", err)
+		return
+	}
+
+	// * cleanup points written by abandoned attempts
+	for _, task := range append(requeued, failed...) {
+		gut.Debug("reaped task %d with expired lease at attempt %d", *task.Id, *task.Attempt)
+		if err := r.deletePoints(task.Id); err != nil {
+			gut.Debug("failed to delete qdrant points of reaped task %d: %v", *task.Id, err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"backend/common/config"
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bsthun/gut"
+)
+
+func TestHeartbeatLeaseLost(t *testing.T) {
+	tests := []struct {
+		name     string
+		leaseErr error
+		lost     bool
+	}{
+		{name: "lease extended", leaseErr: nil, lost: false},
+		{name: "transient error keeps job", leaseErr: errors.New("connection reset"), lost: false},
+		{name: "lease taken over", leaseErr: sql.ErrNoRows, lost: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			querier := &fakeQuerier{leaseErr: test.leaseErr}
+			worker := &Worker{
+				id:       "worker",
+				config:   &config.Config{WorkerLeaseDuration: gut.Ptr(30 * time.Millisecond)},
+				database: &fakeDatabase{querier: querier},
+			}
+
+			ctx, cancel := context.WithCancelCause(context.Background())
+			defer cancel(nil)
+			stop := worker.heartbeat(gut.Ptr(uint64(1)), cancel)
+			select {
+			case <-ctx.Done():
+			case <-time.After(100 * time.Millisecond):
+			}
+			stop()
+
+			if lost := errors.Is(context.Cause(ctx), ErrLeaseLost); lost != test.lost {
+				t.Fatalf("expected lease lost %v, got cause %v", test.lost, context.Cause(ctx))
+			}
+
+			// * aborted job leaves task to new lease holder instead of requeueing it
+			if test.lost {
+				worker.requeue(ctx, gut.Ptr(uint64(1)))
+				if slices.Contains(querier.calls, "requeue") {
+					t.Errorf("expected requeue to be skipped after lease loss, got calls %v", querier.calls)
+				}
+			}
+		})
+	}
+}
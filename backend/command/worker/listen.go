@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bsthun/gut"
+	"github.com/lib/pq"
+)
+
+type Signal struct {
+	ch chan struct{}
+	mu sync.Mutex
+}
+
+func NewSignal() *Signal {
+	return &Signal{
+		ch: make(chan struct{}),
+	}
+}
+
+func (r *Signal) Wait() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ch
+}
+
+func (r *Signal) Broadcast() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	close(r.ch)
+	r.ch = make(chan struct{})
+}
+
+func (r *Worker) listen(ctx context.Context) {
+	// * construct listener with reconnection
+	listener := pq.NewListener(*r.config.PostgresDsn, 1*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			gut.Debug("task listener event %d: %v", event, err)
+		}
+	})
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	if err := listener.Listen("tasks_queuing"); err != nil {
+		gut.Debug("failed to listen for queuing tasks, falling back to polling: %v", err)
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-listener.NotificationChannel():
+			// * nil notification after reconnect also wakes threads to recheck the queue
+			r.signal.Broadcast()
+		}
+	}
+}
+
+func wait(ctx context.Context, wake <-chan struct{}, duration time.Duration) {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-wake:
+	case <-timer.C:
+	}
+}
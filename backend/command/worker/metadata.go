@@ -0,0 +1,53 @@
+package main
+
+import (
+	"backend/generate/psql"
+	"backend/util/metadata"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/bsthun/gut"
+)
+
+func (r *Worker) metadata(ctx context.Context, task *psql.Task, meta *metadata.Metadata, content string) (*string, error) {
+	// * generate title from opening text of content when none exists
+	if meta.Title == nil && r.chat != nil {
+		runes := []rune(content)
+		if len(runes) > 2000 {
+			runes = runes[:2000]
+		}
+		generated, err := r.chat.Complete(ctx, "Write a concise title for the given document in the same language as the document. Respond with the title only, without quotes or extra explanation.", string(runes), false)
+		if err != nil {
+			gut.Debug("failed to generate title of task %d: %v", *task.Id, err)
+		} else {
+			meta.Title = metadata.Clean(strings.Trim(generated, "\"'"), 255)
+			if meta.Title != nil {
+				meta.TitleSource = gut.Ptr(metadata.TitleSourceGenerated)
+			}
+		}
+	}
+
+	// * fallback to opening text of content
+	if meta.Title == nil {
+		runes := []rune(content)
+		if len(runes) > 100 {
+			runes = runes[:100]
+		}
+		meta.Title = gut.Ptr(string(runes))
+		meta.TitleSource = gut.Ptr(metadata.TitleSourceContent)
+	}
+
+	payload, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.database.P().TaskUpdateMetadata(ctx, &psql.TaskUpdateMetadataParams{
+		Id:       task.Id,
+		Metadata: payload,
+	}); err != nil {
+		return nil, err
+	}
+
+	return meta.Title, nil
+}
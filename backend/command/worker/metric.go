@@ -0,0 +1,67 @@
+package main
+
+import (
+	"backend/common/extractor"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/bsthun/gut"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+)
+
+type EndpointCollector struct {
+	pool     *extractor.Pool
+	success  *prometheus.Desc
+	failure  *prometheus.Desc
+	inflight *prometheus.Desc
+}
+
+func NewEndpointCollector(pool *extractor.Pool) *EndpointCollector {
+	return &EndpointCollector{
+		pool:     pool,
+		success:  prometheus.NewDesc("extract_endpoint_success_total", "Number of successful requests to extraction endpoint.", []string{"endpoint"}, nil),
+		failure:  prometheus.NewDesc("extract_endpoint_failure_total", "Number of network and server errors of extraction endpoint.", []string{"endpoint"}, nil),
+		inflight: prometheus.NewDesc("extract_endpoint_inflight", "Number of in-flight requests to extraction endpoint.", []string{"endpoint"}, nil),
+	}
+}
+
+func (r *EndpointCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- r.success
+	ch <- r.failure
+	ch <- r.inflight
+}
+
+func (r *EndpointCollector) Collect(ch chan<- prometheus.Metric) {
+	// * read counters already tracked by pool
+	for _, state := range r.pool.States() {
+		ch <- prometheus.MustNewConstMetric(r.success, prometheus.CounterValue, float64(state.SuccessCount), state.Base)
+		ch <- prometheus.MustNewConstMetric(r.failure, prometheus.CounterValue, float64(state.FailureCount), state.Base)
+		ch <- prometheus.MustNewConstMetric(r.inflight, prometheus.GaugeValue, float64(state.Inflight), state.Base)
+	}
+}
+
+func (r *Worker) serveMetric(lifecycle fx.Lifecycle) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r.metric.Handler())
+	server := &http.Server{
+		Addr:    *r.config.WorkerMetricListen,
+		Handler: mux,
+	}
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					gut.Fatal("unable to listen metrics", err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			_ = server.Shutdown(ctx)
+			return nil
+		},
+	})
+}
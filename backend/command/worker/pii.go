@@ -0,0 +1,50 @@
+package main
+
+import (
+	"backend/generate/psql"
+	"backend/util/metadata"
+	"backend/util/pii"
+	"context"
+	"encoding/json"
+)
+
+func (r *Worker) scrub(ctx context.Context, task *psql.Task, content string, meta *metadata.Metadata) (*string, error) {
+	policy := r.config.Pii
+
+	// * apply override of task category
+	if len(r.config.PiiCategories) > 0 {
+		category, err := r.database.P().CategoryGetById(ctx, task.CategoryId)
+		if err != nil {
+			return nil, err
+		}
+		policy = policy.Merge(r.config.PiiCategories[*category.Name])
+	}
+
+	content, counts := pii.Scrub(content, policy)
+
+	// * redact free text of metadata, title and description are stored and exported with content
+	for _, field := range []**string{&meta.Title, &meta.Author, &meta.Description, &meta.SiteName, &meta.CanonicalUrl} {
+		if *field == nil {
+			continue
+		}
+		scrubbed, fieldCounts := pii.Scrub(**field, policy)
+		for piiType, count := range fieldCounts {
+			counts[piiType] += count
+		}
+		*field = &scrubbed
+	}
+
+	// * record redaction counts of task
+	countsJson, err := json.Marshal(counts)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.database.P().TaskUpdatePii(ctx, &psql.TaskUpdatePiiParams{
+		Id:  task.Id,
+		Pii: countsJson,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &content, nil
+}
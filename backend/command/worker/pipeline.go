@@ -0,0 +1,202 @@
+package main
+
+import (
+	"backend/generate/psql"
+	"backend/util/chunker"
+	"backend/util/dedup"
+	"backend/util/metadata"
+	"backend/util/pipeline"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bsthun/gut"
+	qd "github.com/qdrant/go-client/qdrant"
+)
+
+const (
+	StageClaim     = "claim"
+	StageExtract   = "extract"
+	StageNormalize = "normalize"
+	StageTokenize  = "tokenize"
+	StageEmbed     = "embed"
+	StageDedup     = "dedup"
+	StagePersist   = "persist"
+)
+
+var Stages = []string{StageClaim, StageExtract, StageNormalize, StageTokenize, StageEmbed, StageDedup, StagePersist}
+
+type Job struct {
+	ctx            context.Context
+	cancel         context.CancelCauseFunc
+	task           psql.Task
+	stat           *Stat
+	stopHeartbeat  func()
+	source         *string
+	title          *string
+	content        *string
+	meta           *metadata.Metadata
+	tokenCount     *int32
+	chunks         []*chunker.Chunk
+	policy         *dedup.Policy
+	revisedTask    *psql.Task
+	embeddings     [][]float32
+	points         []*qd.PointStruct
+	duplicateCount int
+}
+
+func (r *Worker) stages(thread int) (map[string]*pipeline.Stage, error) {
+	// * reject override of unknown stage
+	for name := range r.config.WorkerStages {
+		if !slices.Contains(Stages, name) {
+			return nil, fmt.Errorf("unknown worker stage %s", name)
+		}
+	}
+
+	// * each stage defaults to thread flag for both concurrency and queue
+	stages := make(map[string]*pipeline.Stage)
+	for _, name := range Stages {
+		stages[name] = (&pipeline.Stage{
+			Concurrency: gut.Ptr(thread),
+			Queue:       gut.Ptr(thread),
+		}).Merge(r.config.WorkerStages[name])
+	}
+
+	return stages, nil
+}
+
+func (r *Worker) run(claimCtx context.Context, ctx context.Context, stages map[string]*pipeline.Stage) <-chan struct{} {
+	// * construct bounded queue in front of each stage
+	queues := make(map[string]chan *Job)
+	for _, name := range Stages[1:] {
+		queues[name] = make(chan *Job, *stages[name].Queue)
+	}
+	done := make(chan *Job)
+
+	// * claim tasks until claiming stops, then close queue of first stage
+	claimers := new(sync.WaitGroup)
+	for i := 0; i < *stages[StageClaim].Concurrency; i++ {
+		claimers.Add(1)
+		go func() {
+			defer claimers.Done()
+			for claimCtx.Err() == nil {
+				// * take wake channel before claiming to not miss notifications in between
+				wake := r.signal.Wait()
+				job := r.claim(claimCtx, ctx)
+				if job == nil {
+					wait(claimCtx, wake, *r.config.WorkerPollInterval)
+					continue
+				}
+				// * requeue claimed job instead of blocking on full queue once processing is aborted
+				select {
+				case queues[StageExtract] <- job:
+				case <-job.ctx.Done():
+					r.requeue(job.ctx, job.task.Id)
+					r.finish(job)
+				}
+			}
+		}()
+	}
+	go func() {
+		claimers.Wait()
+		close(queues[StageExtract])
+	}()
+
+	// * chain stages, each closing its successor once drained
+	handles := map[string]func(context.Context, *Job) bool{
+		StageExtract:   r.extractStage,
+		StageNormalize: r.normalizeStage,
+		StageTokenize:  r.tokenizeStage,
+		StageEmbed:     r.embedStage,
+		StageDedup:     r.dedupStage,
+		StagePersist:   r.persistStage,
+	}
+	for i, name := range Stages[1:] {
+		out := done
+		if i+2 < len(Stages) {
+			out = queues[Stages[i+2]]
+		}
+		pipeline.Run(queues[name], out, *stages[name].Concurrency, r.stage(handles[name]))
+	}
+
+	// * release jobs leaving last stage
+	drained := make(chan struct{})
+	go func() {
+		for job := range done {
+			r.finish(job)
+		}
+		close(drained)
+	}()
+
+	return drained
+}
+
+func (r *Worker) stage(handle func(context.Context, *Job) bool) func(*Job) bool {
+	return func(job *Job) bool {
+		// * requeue queued job once processing is aborted or lease is lost
+		if job.ctx.Err() != nil {
+			r.requeue(job.ctx, job.task.Id)
+			r.finish(job)
+			return false
+		}
+
+		// * job ended in stage by failure or early completion
+		if !handle(job.ctx, job) {
+			r.finish(job)
+			return false
+		}
+
+		return true
+	}
+}
+
+func (r *Worker) claim(claimCtx context.Context, ctx context.Context) *Job {
+	// * claim pending task of next flow in fair order
+	task, err := r.claimTask(claimCtx)
+	if err != nil {
+		// * no pending tasks or database error, wait for wakeup
+		return nil
+	}
+	r.metric.Claim.Inc()
+
+	// * keep lease alive while job moves through stages, aborting job once lease is lost
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	stopHeartbeat := r.heartbeat(task.Id, cancel)
+
+	return &Job{
+		ctx:    jobCtx,
+		cancel: cancel,
+		task:   task,
+		stat: &Stat{
+			StartedAt:           gut.Ptr(time.Now()),
+			FinishedAt:          nil,
+			ExtractDurations:    nil,
+			TokenCountDurations: nil,
+			SplitDurations:      nil,
+			EmbeddingDurations:  nil,
+			SearchDurations:     nil,
+			UpsertDurations:     nil,
+			ChunkCount:          0,
+		},
+		stopHeartbeat:  stopHeartbeat,
+		source:         task.Source,
+		title:          nil,
+		content:        task.Content,
+		meta:           new(metadata.Metadata),
+		tokenCount:     nil,
+		chunks:         nil,
+		policy:         nil,
+		revisedTask:    nil,
+		embeddings:     nil,
+		points:         nil,
+		duplicateCount: 0,
+	}
+}
+
+func (r *Worker) finish(job *Job) {
+	job.stopHeartbeat()
+	job.cancel(nil)
+	r.saveStat(job.task.Id, job.stat)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"backend/generate/psql"
+	"backend/util/quality"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bsthun/gut"
+)
+
+func (r *Worker) checkQuality(ctx context.Context, task *psql.Task, title *string, content *string) bool {
+	policy := r.config.Quality
+	if *policy.Action == quality.ActionOff {
+		return true
+	}
+
+	// * record quality report of content
+	report := quality.Measure(*content)
+	policy.Evaluate(report)
+	payload, err := json.Marshal(report)
+	if err == nil {
+		err = r.database.P().TaskUpdateQuality(ctx, &psql.TaskUpdateQualityParams{
+			Id:      task.Id,
+			Quality: payload,
+		})
+	}
+	if err != nil {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("quality update error: %v", err)),
+			Title:        title,
+			Content:      content,
+			TokenCount:   nil,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+
+	if len(report.Violations) == 0 {
+		return true
+	}
+
+	// * fail or flag content below thresholds
+	reason := fmt.Sprintf("low quality: %s", strings.Join(report.Violations, ", "))
+	if *policy.Action == quality.ActionFail {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           task.Id,
+			FailedReason: &reason,
+			Title:        title,
+			Content:      content,
+			TokenCount:   nil,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+	r.flag(ctx, &psql.TaskUpdateLowQualityParams{
+		Id:           task.Id,
+		FailedReason: &reason,
+		Title:        title,
+		Content:      content,
+		ClaimedBy:    nil,
+	})
+	return false
+}
@@ -0,0 +1,525 @@
+package main
+
+import (
+	"backend/generate/psql"
+	"backend/util/fingerprint"
+	"backend/util/metadata"
+	"backend/util/resolver"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bsthun/gut"
+	"github.com/go-resty/resty/v2"
+	qd "github.com/qdrant/go-client/qdrant"
+)
+
+func (r *Worker) extractStage(ctx context.Context, job *Job) bool {
+	if job.content != nil {
+		job.content = gut.Ptr(strings.ToValidUTF8(*job.content, ""))
+
+		// * keep title submitted with raw content
+		if job.task.Title != nil {
+			job.meta.Title = metadata.Clean(*job.task.Title, 255)
+		}
+		if job.meta.Title != nil {
+			job.meta.TitleSource = gut.Ptr(metadata.TitleSourceSubmitted)
+		}
+		return true
+	}
+
+	// * resolve share link of source to direct download url
+	var resolvedSource *string
+	if resolved, ok := resolver.Resolve(*job.task.Source); ok {
+		resolvedSource = &resolved
+		job.source = &resolved
+	}
+	var sourceHost *string
+	if host, ok := resolver.Host(*job.task.Source); ok {
+		sourceHost = &host
+	}
+	if err := r.database.P().TaskUpdateResolvedSource(ctx, &psql.TaskUpdateResolvedSourceParams{
+		ResolvedSource: resolvedSource,
+		SourceHost:     sourceHost,
+		Id:             job.task.Id,
+	}); err != nil {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("resolved source update error: %v", err)),
+			Title:        nil,
+			Content:      nil,
+			TokenCount:   nil,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+
+	if r.robots != nil {
+		// * respect robots.txt of source, unreachable robots.txt does not block extraction
+		allowed, err := r.robots.Allowed(ctx, *job.source)
+		if err != nil {
+			gut.Debug("failed to check robots.txt of task %d: %v", *job.task.Id, err)
+		} else if !allowed {
+			r.fail(ctx, &psql.TaskUpdateFailedParams{
+				Id:           job.task.Id,
+				FailedReason: gut.Ptr(fmt.Sprintf("robots.txt disallows %s", *job.source)),
+				Title:        nil,
+				Content:      nil,
+				TokenCount:   nil,
+				ClaimedBy:    nil,
+			})
+			return false
+		}
+	}
+
+	// * extract content of pdf text layer or with registered extractor of task type
+	extractStart := time.Now()
+	extractCtx, cancel := context.WithTimeout(ctx, *r.config.WorkerExtractTimeout)
+	extractResp, err := r.extract(extractCtx, &job.task, *job.source)
+	cancel()
+	job.stat.ExtractDurations = append(job.stat.ExtractDurations, gut.Ptr(time.Since(extractStart)))
+	if err != nil {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(err.Error()),
+			Title:        nil,
+			Content:      nil,
+			TokenCount:   nil,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+
+	job.content = gut.Ptr(strings.ToValidUTF8(extractResp.Text, ""))
+
+	// * keep metadata and title given by extractor
+	if extractResp.Metadata != nil {
+		job.meta = extractResp.Metadata
+	}
+	job.meta.Title = metadata.Clean(strings.ToValidUTF8(extractResp.Title, ""), 255)
+	if job.meta.Title != nil {
+		job.meta.TitleSource = gut.Ptr(metadata.TitleSourceExtractor)
+	}
+
+	// * redact personal information of content and metadata before they are stored
+	job.content, err = r.scrub(ctx, &job.task, *job.content, job.meta)
+	if err != nil {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("pii redaction error: %v", err)),
+			Title:        nil,
+			Content:      nil,
+			TokenCount:   nil,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+
+	// * record extractor which produced content
+	if err := r.database.P().TaskUpdateExtractor(ctx, &psql.TaskUpdateExtractorParams{
+		Id:        job.task.Id,
+		Extractor: &extractResp.Extractor,
+		OcrPages:  extractResp.OcrPages,
+	}); err != nil {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("extractor update error: %v", err)),
+			Title:        nil,
+			Content:      job.content,
+			TokenCount:   nil,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+
+	return true
+}
+
+func (r *Worker) normalizeStage(ctx context.Context, job *Job) bool {
+	// * measure text quality before deduplication and embedding
+	if !r.checkQuality(ctx, &job.task, job.meta.Title, job.content) {
+		return false
+	}
+
+	// * clear duplicates of previous attempt
+	if err := r.database.P().TaskDuplicateDeleteByTaskId(ctx, job.task.Id); err != nil {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("duplicate cleanup error: %v", err)),
+			Title:        job.meta.Title,
+			Content:      job.content,
+			TokenCount:   nil,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+
+	// * record normalized content hash
+	contentSha256 := fingerprint.ContentSha256(*job.content)
+	if err := r.database.P().TaskUpdateContentSha256(ctx, &psql.TaskUpdateContentSha256Params{
+		Id:            job.task.Id,
+		ContentSha256: &contentSha256,
+	}); err != nil {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("content hash update error: %v", err)),
+			Title:        job.meta.Title,
+			Content:      job.content,
+			TokenCount:   nil,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+
+	// * short-circuit exact duplicate of completed task before tokenization and embedding
+	exactTask, err := r.database.P().TaskGetExactDuplicate(ctx, &psql.TaskGetExactDuplicateParams{
+		ContentSha256: &contentSha256,
+		Type:          job.task.Type,
+		Id:            job.task.Id,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("exact duplicate lookup error: %v", err)),
+			Title:        job.meta.Title,
+			Content:      job.content,
+			TokenCount:   nil,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+	if err == nil {
+		r.metric.Duplicate.WithLabelValues("exact").Inc()
+		if err := r.saveDuplicate(ctx, job.task.Id, exactTask.Id, nil, 1); err != nil {
+			gut.Debug("failed to save exact duplicate of task %d: %v", *job.task.Id, err)
+		}
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("exact duplicate #%s (content sha256)", gut.EncodeId(*exactTask.Id))),
+			Title:        job.meta.Title,
+			Content:      job.content,
+			TokenCount:   nil,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+
+	// * resolve title of metadata after exact duplicate is skipped, generating title when none exists
+	title, err := r.metadata(ctx, &job.task, job.meta, *job.content)
+	if err != nil {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("metadata update error: %v", err)),
+			Title:        job.meta.Title,
+			Content:      job.content,
+			TokenCount:   nil,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+	job.title = title
+
+	// * suggest category of content after exact duplicate is skipped, recategorizing task above threshold
+	if r.classifier != nil {
+		r.classify(ctx, &job.task, *job.content)
+	}
+
+	return true
+}
+
+func (r *Worker) tokenizeStage(ctx context.Context, job *Job) bool {
+	// * call tokenization service
+	tokenResp := new(TokenResponse)
+	tokenPayload := map[string]string{
+		"text": *job.content,
+	}
+
+	tokenCountStart := time.Now()
+	tokenCountCtx, cancel := context.WithTimeout(ctx, *r.config.WorkerTokenCountTimeout)
+	resp, err := resty.New().R().
+		SetContext(tokenCountCtx).
+		SetHeader("Content-Type", "application/x-www-form-urlencoded").
+		SetFormData(tokenPayload).
+		SetResult(&tokenResp).
+		Post(*r.config.EndpointTokenCount)
+	cancel()
+	job.stat.TokenCountDurations = append(job.stat.TokenCountDurations, gut.Ptr(time.Since(tokenCountStart)))
+	if err != nil {
+		// * network error for tokenization
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("token error: %v", err)),
+			Title:        job.title,
+			Content:      job.content,
+			TokenCount:   nil,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+
+	// * handle server error
+	if resp.StatusCode() >= 500 {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("tokenization %d (%s)", resp.StatusCode(), resp.Body())),
+			Title:        job.title,
+			Content:      job.content,
+			TokenCount:   nil,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+	job.tokenCount = &tokenResp.TokenCount
+
+	// * record chunking strategy for reproducible re-embedding
+	chunking, err := json.Marshal(r.chunker.Strategy())
+	if err != nil {
+		gut.Fatal("failed to marshal chunking strategy", err)
+	}
+	if err := r.database.P().TaskUpdateChunking(ctx, &psql.TaskUpdateChunkingParams{
+		Id:       job.task.Id,
+		Chunking: chunking,
+	}); err != nil {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("chunking update error: %v", err)),
+			Title:        job.title,
+			Content:      job.content,
+			TokenCount:   job.tokenCount,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+
+	// * split content to chunks
+	splitStart := time.Now()
+	job.chunks, err = r.chunker.Chunks(*job.content)
+	job.stat.SplitDurations = append(job.stat.SplitDurations, gut.Ptr(time.Since(splitStart)))
+	job.stat.ChunkCount = len(job.chunks)
+	if err != nil {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("text splitting error: %v", err)),
+			Title:        job.title,
+			Content:      job.content,
+			TokenCount:   job.tokenCount,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+
+	return true
+}
+
+func (r *Worker) embedStage(ctx context.Context, job *Job) bool {
+	job.embeddings = make([][]float32, 0, len(job.chunks))
+	for offset := 0; offset < len(job.chunks); offset += *r.config.WorkerEmbeddingBatchSize {
+		batch := job.chunks[offset:min(offset+*r.config.WorkerEmbeddingBatchSize, len(job.chunks))]
+		inputs := make([]string, 0, len(batch))
+		for _, chunk := range batch {
+			inputs = append(inputs, chunk.Text)
+		}
+
+		// * get embeddings of chunk batch
+		embeddingStart := time.Now()
+		embeddings, err := r.embed(ctx, inputs)
+		job.stat.EmbeddingDurations = append(job.stat.EmbeddingDurations, gut.Ptr(time.Since(embeddingStart)))
+		if err != nil {
+			r.fail(ctx, &psql.TaskUpdateFailedParams{
+				Id:           job.task.Id,
+				FailedReason: gut.Ptr(fmt.Sprintf("embedding error: %v", err)),
+				Title:        job.title,
+				Content:      job.content,
+				TokenCount:   job.tokenCount,
+				ClaimedBy:    nil,
+			})
+			return false
+		}
+		job.embeddings = append(job.embeddings, embeddings...)
+	}
+
+	return true
+}
+
+func (r *Worker) dedupStage(ctx context.Context, job *Job) bool {
+	// * resolve dedup policy of task category
+	policy, err := r.dedupProcedure.DedupResolve(ctx, r.database.P(), &job.task)
+	if err != nil {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("dedup policy error: %v", err)),
+			Title:        job.title,
+			Content:      job.content,
+			TokenCount:   job.tokenCount,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+	job.policy = policy
+
+	// * clear chunks of previous attempt
+	if err := r.database.P().TaskChunkDeleteByTaskId(ctx, job.task.Id); err != nil {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("chunk cleanup error: %v", err)),
+			Title:        job.title,
+			Content:      job.content,
+			TokenCount:   job.tokenCount,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+
+	job.points = make([]*qd.PointStruct, 0, len(job.chunks))
+	for offset := 0; offset < len(job.chunks); offset += *r.config.WorkerEmbeddingBatchSize {
+		end := min(offset+*r.config.WorkerEmbeddingBatchSize, len(job.chunks))
+		batch := job.chunks[offset:end]
+		embeddings := job.embeddings[offset:end]
+
+		// * search in qdrant for similarity
+		searchStart := time.Now()
+		searchResults, err := r.search(ctx, &job.task, job.policy, embeddings)
+		job.stat.SearchDurations = append(job.stat.SearchDurations, gut.Ptr(time.Since(searchStart)))
+		if err != nil {
+			r.fail(ctx, &psql.TaskUpdateFailedParams{
+				Id:           job.task.Id,
+				FailedReason: gut.Ptr(fmt.Sprintf("qdrant search error: %v", err)),
+				Title:        job.title,
+				Content:      job.content,
+				TokenCount:   job.tokenCount,
+				ClaimedBy:    nil,
+			})
+			return false
+		}
+
+		for j, embedding := range embeddings {
+			var match *qd.ScoredPoint
+
+			// * check if duplicate found
+			if searchResult := searchResults[j].Result; len(searchResult) > 0 {
+				match = searchResult[0]
+
+				// * extract duplicate taskId
+				duplicateTaskId, err := strconv.ParseUint(searchResult[0].Payload["taskId"].GetStringValue(), 10, 64)
+				if err != nil {
+					gut.Fatal("failed to parse duplicate taskId", err)
+				}
+
+				// * record duplicate relationship of chunk
+				if err := r.saveDuplicate(ctx, job.task.Id, &duplicateTaskId, gut.Ptr(int32(batch[j].No)), float64(match.Score)); err != nil {
+					r.fail(ctx, &psql.TaskUpdateFailedParams{
+						Id:           job.task.Id,
+						FailedReason: gut.Ptr(fmt.Sprintf("duplicate save error: %v", err)),
+						Title:        job.title,
+						Content:      job.content,
+						TokenCount:   job.tokenCount,
+						ClaimedBy:    nil,
+					})
+					return false
+				}
+
+				// * get duplicate task
+				duplicateTask, err := r.database.P().TaskGetById(ctx, gut.Ptr(duplicateTaskId))
+				if err != nil {
+					r.fail(ctx, &psql.TaskUpdateFailedParams{
+						Id:           job.task.Id,
+						FailedReason: gut.Ptr(fmt.Sprintf("duplicate task lookup error: %v", err)),
+						Title:        job.title,
+						Content:      job.content,
+						TokenCount:   job.tokenCount,
+						ClaimedBy:    nil,
+					})
+					return false
+				} else if *duplicateTask.Task.Status == "ignored" && (job.revisedTask == nil || *job.revisedTask.Id == duplicateTaskId) {
+					// * record matched chunk without point, points are taken over from ignored task once persisted
+					job.revisedTask = &duplicateTask.Task
+					if err := r.chunkProcedure.ChunkSave(ctx, r.database.P(), job.task.Id, batch[j], nil, match); err != nil {
+						r.fail(ctx, &psql.TaskUpdateFailedParams{
+							Id:           job.task.Id,
+							FailedReason: gut.Ptr(fmt.Sprintf("chunk save error: %v", err)),
+							Title:        job.title,
+							Content:      job.content,
+							TokenCount:   job.tokenCount,
+							ClaimedBy:    nil,
+						})
+						return false
+					}
+					continue
+				} else {
+					// * duplicate task is not ignored
+					job.duplicateCount++
+				}
+			}
+
+			point := r.point(&job.task, batch[j].No, embedding)
+			job.points = append(job.points, point)
+
+			// * record chunk with its point and duplicate match
+			if err := r.chunkProcedure.ChunkSave(ctx, r.database.P(), job.task.Id, batch[j], gut.Ptr(point.Id.GetUuid()), match); err != nil {
+				r.fail(ctx, &psql.TaskUpdateFailedParams{
+					Id:           job.task.Id,
+					FailedReason: gut.Ptr(fmt.Sprintf("chunk save error: %v", err)),
+					Title:        job.title,
+					Content:      job.content,
+					TokenCount:   job.tokenCount,
+					ClaimedBy:    nil,
+				})
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (r *Worker) persistStage(ctx context.Context, job *Job) bool {
+	// * complete as revision of ignored task, taking over its points
+	if job.revisedTask != nil {
+		return r.takeover(ctx, job)
+	}
+
+	// * reject near duplicate before points are written
+	if job.policy.Duplicate(job.duplicateCount, len(job.chunks)) {
+		r.metric.Duplicate.WithLabelValues("near").Inc()
+
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("duplicate in %d of %d chunks", job.duplicateCount, len(job.chunks))),
+			Title:        job.title,
+			Content:      job.content,
+			TokenCount:   job.tokenCount,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+
+	// * upsert points of all chunks
+	if err := r.upsert(ctx, job.stat, job.points); err != nil {
+		r.fail(ctx, &psql.TaskUpdateFailedParams{
+			Id:           job.task.Id,
+			FailedReason: gut.Ptr(fmt.Sprintf("qdrant upsert error: %v", err)),
+			Title:        job.title,
+			Content:      job.content,
+			TokenCount:   job.tokenCount,
+			ClaimedBy:    nil,
+		})
+		return false
+	}
+
+	// * update task as completed
+	r.complete(&psql.TaskUpdateCompletedParams{
+		Id:            job.task.Id,
+		Title:         job.title,
+		Content:       job.content,
+		TokenCount:    job.tokenCount,
+		RevisedTaskId: nil,
+		ClaimedBy:     nil,
+	})
+
+	return true
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"backend/common/config"
+	"backend/common/metric"
+	"backend/generate/psql"
+	"backend/util/metadata"
+	"backend/util/quality"
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/bsthun/gut"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestNormalizeStageExactDuplicate(t *testing.T) {
+	if err := gut.SetIdEncoderKey([]byte("0123456789abcdef")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		name   string
+		exact  *psql.Task
+		passed bool
+		reason string
+		calls  []string
+	}{
+		{
+			name:   "unique content continues to tokenization",
+			exact:  nil,
+			passed: true,
+			calls:  []string{"duplicate delete", "content sha256", "exact duplicate", "metadata"},
+		},
+		{
+			name:   "exact duplicate fails with original task id before metadata",
+			exact:  &psql.Task{Id: gut.Ptr(uint64(7))},
+			passed: false,
+			reason: fmt.Sprintf("exact duplicate #%s (content sha256)", gut.EncodeId(7)),
+			calls:  []string{"duplicate delete", "content sha256", "exact duplicate", "duplicate create", "failed"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			querier := &fakeQuerier{exact: test.exact}
+			worker := &Worker{
+				id:       "worker",
+				config:   &config.Config{Quality: &quality.Policy{Action: gut.Ptr(quality.ActionOff)}},
+				database: &fakeDatabase{querier: querier},
+				metric: &metric.Metric{
+					Duplicate: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "task_duplicate_total"}, []string{"kind"}),
+				},
+			}
+			job := &Job{
+				task:    psql.Task{Id: gut.Ptr(uint64(9)), Type: gut.Ptr("web")},
+				content: gut.Ptr("normalized content of task"),
+				meta:    new(metadata.Metadata),
+			}
+
+			if passed := worker.normalizeStage(context.Background(), job); passed != test.passed {
+				t.Fatalf("expected passed %v, got %v", test.passed, passed)
+			}
+			if !slices.Equal(querier.calls, test.calls) {
+				t.Errorf("expected calls %v, got %v", test.calls, querier.calls)
+			}
+			if test.exact == nil {
+				if job.title == nil || *job.title != "normalized content of task" {
+					t.Errorf("expected title from content, got %v", job.title)
+				}
+				return
+			}
+			if len(querier.failed) != 1 || *querier.failed[0].FailedReason != test.reason {
+				t.Fatalf("expected failure %q, got %+v", test.reason, querier.failed)
+			}
+			if len(querier.duplicates) != 1 || *querier.duplicates[0].DuplicateOfTaskId != *test.exact.Id || *querier.duplicates[0].Score != 1 {
+				t.Errorf("expected exact duplicate of task %d recorded, got %+v", *test.exact.Id, querier.duplicates)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"backend/generate/psql"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/bsthun/gut"
+)
+
+type Stat struct {
+	StartedAt           *time.Time       `json:"startedAt"`
+	FinishedAt          *time.Time       `json:"finishedAt"`
+	ExtractDurations    []*time.Duration `json:"extractDurations"`
+	TokenCountDurations []*time.Duration `json:"tokenCountDurations"`
+	SplitDurations      []*time.Duration `json:"splitDurations"`
+	EmbeddingDurations  []*time.Duration `json:"embeddingDurations"`
+	SearchDurations     []*time.Duration `json:"searchDurations"`
+	UpsertDurations     []*time.Duration `json:"upsertDurations"`
+	ChunkCount          int              `json:"chunkCount"`
+}
+
+func (r *Worker) saveStat(taskId *uint64, stat *Stat) {
+	stat.FinishedAt = gut.Ptr(time.Now())
+
+	// * observe stage durations
+	for stage, durations := range map[string][]*time.Duration{
+		"extract":     stat.ExtractDurations,
+		"token_count": stat.TokenCountDurations,
+		"split":       stat.SplitDurations,
+		"embedding":   stat.EmbeddingDurations,
+		"search":      stat.SearchDurations,
+		"upsert":      stat.UpsertDurations,
+	} {
+		for _, duration := range durations {
+			r.metric.StageDuration.WithLabelValues(stage).Observe(duration.Seconds())
+		}
+	}
+
+	// * marshal stat detail
+	detail, err := json.Marshal(stat)
+	if err != nil {
+		gut.Debug("failed to marshal stat of task %d: %v", *taskId, err)
+		return
+	}
+
+	// * persist stat
+	if err := r.database.P().TaskStatUpsert(context.Background(), &psql.TaskStatUpsertParams{
+		TaskId:             taskId,
+		StartedAt:          stat.StartedAt,
+		FinishedAt:         stat.FinishedAt,
+		ExtractDuration:    milliseconds(stat.ExtractDurations),
+		TokenCountDuration: milliseconds(stat.TokenCountDurations),
+		SplitDuration:      milliseconds(stat.SplitDurations),
+		EmbeddingDuration:  milliseconds(stat.EmbeddingDurations),
+		SearchDuration:     milliseconds(stat.SearchDurations),
+		UpsertDuration:     milliseconds(stat.UpsertDurations),
+		ChunkCount:         gut.Ptr(int32(stat.ChunkCount)),
+		Detail:             detail,
+	}); err != nil {
+		gut.Debug("failed to save stat of task %d: %v", *taskId, err)
+	}
+}
+
+func milliseconds(durations []*time.Duration) *uint64 {
+	// * stage never ran
+	if len(durations) == 0 {
+		return nil
+	}
+
+	var total time.Duration
+	for _, duration := range durations {
+		total += *duration
+	}
+
+	return gut.Ptr(uint64(total.Milliseconds()))
+}
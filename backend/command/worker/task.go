@@ -0,0 +1,127 @@
+package main
+
+import (
+	"backend/generate/psql"
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/bsthun/gut"
+	qd "github.com/qdrant/go-client/qdrant"
+)
+
+func (r *Worker) fail(ctx context.Context, params *psql.TaskUpdateFailedParams) {
+	// * requeue instead of failing when processing is aborted by shutdown
+	if ctx.Err() != nil {
+		r.requeue(ctx, params.Id)
+		return
+	}
+
+	// * update only while lease is still held by this worker
+	params.ClaimedBy = &r.id
+	rows, err := r.database.P().TaskUpdateFailed(context.Background(), params)
+	if err != nil {
+		gut.Fatal("failed to update task as failed", err)
+	}
+	if rows == 0 {
+		gut.Debug("lost lease of task %d, skipping failure", *params.Id)
+		return
+	}
+	r.metric.TaskResult.WithLabelValues("failed").Inc()
+
+	// * cleanup partially upserted points
+	if err := r.deletePoints(params.Id); err != nil {
+		gut.Debug("failed to delete qdrant points of failed task %d: %v", *params.Id, err)
+	}
+}
+
+func (r *Worker) flag(ctx context.Context, params *psql.TaskUpdateLowQualityParams) {
+	// * requeue instead of flagging when processing is aborted by shutdown
+	if ctx.Err() != nil {
+		r.requeue(ctx, params.Id)
+		return
+	}
+
+	// * update only while lease is still held by this worker
+	params.ClaimedBy = &r.id
+	rows, err := r.database.P().TaskUpdateLowQuality(context.Background(), params)
+	if err != nil {
+		gut.Fatal("failed to update task as low quality", err)
+	}
+	if rows == 0 {
+		gut.Debug("lost lease of task %d, skipping low quality flag", *params.Id)
+		return
+	}
+	r.metric.TaskResult.WithLabelValues("low_quality").Inc()
+}
+
+func (r *Worker) complete(params *psql.TaskUpdateCompletedParams) {
+	// * update only while lease is still held by this worker
+	params.ClaimedBy = &r.id
+	rows, err := r.database.P().TaskUpdateCompleted(context.Background(), params)
+	if err != nil {
+		gut.Fatal("failed to update task as completed", err)
+	}
+	if rows == 0 {
+		gut.Debug("lost lease of task %d, skipping completion", *params.Id)
+		return
+	}
+	r.metric.TaskResult.WithLabelValues("completed").Inc()
+}
+
+func (r *Worker) requeue(ctx context.Context, taskId *uint64) {
+	// * leave task and its points to the worker holding the lease now
+	if errors.Is(context.Cause(ctx), ErrLeaseLost) {
+		gut.Debug("lost lease of task %d, skipping requeue", *taskId)
+		return
+	}
+
+	// * cleanup partially upserted points
+	if err := r.deletePoints(taskId); err != nil {
+		gut.Debug("failed to delete qdrant points of aborted task %d: %v", *taskId, err)
+	}
+
+	if _, err := r.database.P().TaskRequeue(context.Background(), &psql.TaskRequeueParams{
+		Id:        taskId,
+		ClaimedBy: &r.id,
+	}); err != nil {
+		gut.Debug("failed to requeue aborted task %d: %v", *taskId, err)
+		return
+	}
+
+	gut.Debug("requeued aborted task %d", *taskId)
+}
+
+func (r *Worker) deletePoints(taskId *uint64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), *r.config.WorkerQdrantTimeout)
+	defer cancel()
+
+	if _, err := r.qdrantClient.Delete(ctx, &qd.DeletePoints{
+		CollectionName: *r.config.QdrantCollection,
+		Points: &qd.PointsSelector{
+			PointsSelectorOneOf: &qd.PointsSelector_Filter{
+				Filter: &qd.Filter{
+					Must: []*qd.Condition{
+						{
+							ConditionOneOf: &qd.Condition_Field{
+								Field: &qd.FieldCondition{
+									Key: "taskId",
+									Match: &qd.Match{
+										MatchValue: &qd.Match_Keyword{
+											Keyword: strconv.FormatUint(*taskId, 10),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}); err != nil {
+		return err
+	}
+
+	// * detach deleted points from recorded chunks
+	return r.database.P().TaskChunkDetachPoints(context.Background(), taskId)
+}
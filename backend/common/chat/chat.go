@@ -0,0 +1,27 @@
+package chat
+
+import (
+	"backend/common/config"
+	"context"
+	"fmt"
+)
+
+const (
+	ProviderOllama = "ollama"
+	ProviderOpenai = "openai"
+)
+
+type Chat interface {
+	Complete(ctx context.Context, system string, user string, json bool) (string, error)
+}
+
+func New(config *config.Config, provider string) (Chat, error) {
+	switch provider {
+	case ProviderOllama:
+		return NewOllama(config)
+	case ProviderOpenai:
+		return NewOpenai(config)
+	default:
+		return nil, fmt.Errorf("unknown chat provider %s", provider)
+	}
+}
@@ -0,0 +1,68 @@
+package chat
+
+import (
+	"backend/common/config"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/bsthun/gut"
+	"github.com/ollama/ollama/api"
+)
+
+type Ollama struct {
+	client *api.Client
+	model  string
+}
+
+func NewOllama(config *config.Config) (*Ollama, error) {
+	baseUrl, err := url.Parse(*config.OllamaBaseUrl)
+	if err != nil {
+		return nil, gut.Err(false, "failed to parse ollama url", err)
+	}
+
+	httpClient := &http.Client{
+		Timeout: 60 * time.Second,
+	}
+
+	return &Ollama{
+		client: api.NewClient(baseUrl, httpClient),
+		model:  *config.OllamaModel,
+	}, nil
+}
+
+func (r *Ollama) Complete(ctx context.Context, system string, user string, jsonFormat bool) (string, error) {
+	request := &api.ChatRequest{
+		Model: r.model,
+		Messages: []api.Message{
+			{
+				Role:    "system",
+				Content: system,
+			},
+			{
+				Role:    "user",
+				Content: user,
+			},
+		},
+		Stream: gut.Ptr(false),
+		Format: nil,
+		Options: map[string]any{
+			"temperature": 0,
+		},
+	}
+	if jsonFormat {
+		request.Format = json.RawMessage(`"json"`)
+	}
+
+	content := ""
+	if err := r.client.Chat(ctx, request, func(resp api.ChatResponse) error {
+		content += resp.Message.Content
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	return content, nil
+}
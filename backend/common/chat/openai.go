@@ -0,0 +1,52 @@
+package chat
+
+import (
+	"backend/common/config"
+	oai "backend/common/openai"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/shared"
+)
+
+type Openai struct {
+	client *openai.Client
+	model  string
+}
+
+func NewOpenai(config *config.Config) (*Openai, error) {
+	return &Openai{
+		client: oai.New(*config.OpenaiBaseUrl, *config.OpenaiApiKey, 60*time.Second),
+		model:  *config.OpenaiModel,
+	}, nil
+}
+
+func (r *Openai) Complete(ctx context.Context, system string, user string, jsonFormat bool) (string, error) {
+	params := openai.ChatCompletionNewParams{
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(system),
+			openai.UserMessage(user),
+		},
+		Model:       r.model,
+		Temperature: openai.Float(0),
+	}
+	if jsonFormat {
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONObject: &shared.ResponseFormatJSONObjectParam{
+				Type: "json_object",
+			},
+		}
+	}
+
+	chatCompletion, err := r.client.Chat.Completions.New(ctx, params)
+	if err != nil {
+		return "", err
+	}
+	if len(chatCompletion.Choices) == 0 {
+		return "", fmt.Errorf("empty chat completion")
+	}
+
+	return chatCompletion.Choices[0].Message.Content, nil
+}
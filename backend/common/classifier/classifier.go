@@ -0,0 +1,75 @@
+package classifier
+
+import (
+	"backend/common/chat"
+	"backend/common/config"
+	"backend/generate/psql"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type Classifier struct {
+	chat chat.Chat
+}
+
+type Suggestion struct {
+	Category   string  `json:"category"`
+	CategoryId *uint64 `json:"-"`
+	Confidence float64 `json:"confidence"`
+}
+
+func New(config *config.Config) (*Classifier, error) {
+	provider, err := chat.New(config, *config.WorkerClassifyProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Classifier{
+		chat: provider,
+	}, nil
+}
+
+func (r *Classifier) Classify(ctx context.Context, categories []psql.Category, text string) (*Suggestion, error) {
+	names := make([]string, 0, len(categories))
+	for _, category := range categories {
+		names = append(names, *category.Name)
+	}
+	content, err := r.chat.Complete(ctx, prompt(names), text, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(categories, content)
+}
+
+func prompt(categories []string) string {
+	return fmt.Sprintf("Classify the document given by user into exactly one of the following categories: %s. Respond only with a JSON object {\"category\": \"<category name>\", \"confidence\": <number between 0 and 1>} without any explanation.", strings.Join(categories, ", "))
+}
+
+func parse(categories []psql.Category, content string) (*Suggestion, error) {
+	// * tolerate text around json object of chat response
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("invalid classification response %q", content)
+	}
+
+	suggestion := new(Suggestion)
+	if err := json.Unmarshal([]byte(content[start:end+1]), suggestion); err != nil {
+		return nil, fmt.Errorf("invalid classification response: %v", err)
+	}
+
+	// * suggested category must be one of given categories, matched case-insensitively
+	for _, category := range categories {
+		if strings.EqualFold(*category.Name, strings.TrimSpace(suggestion.Category)) {
+			suggestion.Category = *category.Name
+			suggestion.CategoryId = category.Id
+			suggestion.Confidence = min(max(suggestion.Confidence, 0), 1)
+			return suggestion, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown suggested category %q", suggestion.Category)
+}
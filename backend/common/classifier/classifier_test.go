@@ -0,0 +1,51 @@
+package classifier
+
+import (
+	"backend/generate/psql"
+	"testing"
+
+	"github.com/bsthun/gut"
+)
+
+func TestParse(t *testing.T) {
+	categories := []psql.Category{
+		{Id: gut.Ptr(uint64(1)), Name: gut.Ptr("News")},
+		{Id: gut.Ptr(uint64(2)), Name: gut.Ptr("law")},
+	}
+	tests := []struct {
+		name       string
+		content    string
+		category   string
+		categoryId uint64
+		confidence float64
+		invalid    bool
+	}{
+		{name: "exact name", content: `{"category": "News", "confidence": 0.8}`, category: "News", categoryId: 1, confidence: 0.8},
+		{name: "different case", content: `{"category": " news ", "confidence": 0.6}`, category: "News", categoryId: 1, confidence: 0.6},
+		{name: "text around json", content: "Sure: {\"category\": \"LAW\", \"confidence\": 0.9} done", category: "law", categoryId: 2, confidence: 0.9},
+		{name: "confidence clamped", content: `{"category": "law", "confidence": 1.7}`, category: "law", categoryId: 2, confidence: 1},
+		{name: "unknown category", content: `{"category": "sport", "confidence": 0.9}`, invalid: true},
+		{name: "no json", content: "news", invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			suggestion, err := parse(categories, test.content)
+			if test.invalid {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", suggestion)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if suggestion.Category != test.category || suggestion.CategoryId == nil || *suggestion.CategoryId != test.categoryId {
+				t.Errorf("expected category %s #%d, got %s %v", test.category, test.categoryId, suggestion.Category, suggestion.CategoryId)
+			}
+			if suggestion.Confidence != test.confidence {
+				t.Errorf("expected confidence %v, got %v", test.confidence, suggestion.Confidence)
+			}
+		})
+	}
+}
@@ -2,36 +2,87 @@ package config
 
 import (
 	"backend/type/enum"
+	"backend/util/chunker"
+	"backend/util/dedup"
+	"backend/util/pii"
+	"backend/util/pipeline"
+	"backend/util/politeness"
+	"backend/util/quality"
 	"github.com/bsthun/gut"
 	"gopkg.in/yaml.v3"
 	"os"
+	"time"
 )
 
 type Config struct {
-	Environment          *enum.Environment `yaml:"environment" validate:"required"`
-	WebRoot              *string           `yaml:"webRoot" validate:"omitempty"`
-	WebListen            [2]*string        `yaml:"webListen" validate:"required"`
-	FrontendUrl          *string           `yaml:"frontendUrl" validate:"required"`
-	Secret               *string           `yaml:"secret" validate:"required"`
-	PostgresDsn          *string           `yaml:"postgresDsn" validate:"required"`
-	QdrantDsn            *string           `yaml:"qdrantDsn" validate:"required"`
-	QdrantCollection     *string           `yaml:"qdrantCollection" validate:"required"`
-	QdrantApiKey         *string           `yaml:"qdrantApiKey" validate:"required"`
-	OllamaBaseUrl        *string           `yaml:"ollamaBaseUrl" validate:"required"`
-	OllamaModel          *string           `yaml:"ollamaModel" validate:"required"`
-	OllamaEmbeddingModel *string           `yaml:"ollamaEmbeddingModel" validate:"required"`
-	OauthClientId        *string           `yaml:"oauthClientId" validate:"required"`
-	OauthClientSecret    *string           `yaml:"oauthClientSecret" validate:"required"`
-	OauthEndpoint        *string           `yaml:"oauthEndpoint" validate:"required"`
-	EndpointEmbedding    *string           `yaml:"endpointEmbedding" validate:"required"`
-	EndpointTokenCount   *string           `yaml:"endpointTokenCount" validate:"required"`
-	EndpointExtracts     []*string         `yaml:"endpointExtracts" validate:"required"`
-	EndpointWebPath      *string           `yaml:"endpointWebPath" validate:"required"`
-	EndpointDocPath      *string           `yaml:"endpointDocPath" validate:"required"`
-	EndpointYoutubePath  *string           `yaml:"endpointYoutubePath" validate:"required"`
-	OpenaiBaseUrl        *string           `yaml:"openaiBaseUrl" validate:"required"`
-	OpenaiModel          *string           `yaml:"openaiModel" validate:"required"`
-	OpenaiApiKey         *string           `yaml:"openaiApiKey" validate:"required"`
+	Environment                   *enum.Environment            `yaml:"environment" validate:"required"`
+	WebRoot                       *string                      `yaml:"webRoot" validate:"omitempty"`
+	WebListen                     [2]*string                   `yaml:"webListen" validate:"required"`
+	WebMetricListen               *string                      `yaml:"webMetricListen" validate:"omitempty"`
+	FrontendUrl                   *string                      `yaml:"frontendUrl" validate:"required"`
+	Secret                        *string                      `yaml:"secret" validate:"required"`
+	PostgresDsn                   *string                      `yaml:"postgresDsn" validate:"required"`
+	QdrantDsn                     *string                      `yaml:"qdrantDsn" validate:"required"`
+	QdrantCollection              *string                      `yaml:"qdrantCollection" validate:"required"`
+	QdrantApiKey                  *string                      `yaml:"qdrantApiKey" validate:"required"`
+	OllamaBaseUrl                 *string                      `yaml:"ollamaBaseUrl" validate:"required"`
+	OllamaModel                   *string                      `yaml:"ollamaModel" validate:"required"`
+	OllamaEmbeddingModel          *string                      `yaml:"ollamaEmbeddingModel" validate:"required"`
+	OauthClientId                 *string                      `yaml:"oauthClientId" validate:"required"`
+	OauthClientSecret             *string                      `yaml:"oauthClientSecret" validate:"required"`
+	OauthEndpoint                 *string                      `yaml:"oauthEndpoint" validate:"required"`
+	EndpointEmbedding             *string                      `yaml:"endpointEmbedding" validate:"required_if=EmbeddingProvider openai"`
+	EndpointTokenCount            *string                      `yaml:"endpointTokenCount" validate:"required"`
+	EndpointExtracts              []*string                    `yaml:"endpointExtracts" validate:"required"`
+	EndpointWebPath               *string                      `yaml:"endpointWebPath" validate:"required"`
+	EndpointDocPath               *string                      `yaml:"endpointDocPath" validate:"required"`
+	EndpointYoutubePath           *string                      `yaml:"endpointYoutubePath" validate:"required"`
+	EndpointExtractCapacities     map[string]*int              `yaml:"endpointExtractCapacities" validate:"omitempty,dive,omitempty,gte=1"`
+	EndpointPaths                 map[string]*string           `yaml:"endpointPaths" validate:"omitempty"`
+	OpenaiBaseUrl                 *string                      `yaml:"openaiBaseUrl" validate:"required"`
+	OpenaiModel                   *string                      `yaml:"openaiModel" validate:"required"`
+	OpenaiApiKey                  *string                      `yaml:"openaiApiKey" validate:"required"`
+	EmbeddingProvider             *string                      `yaml:"embeddingProvider" validate:"omitempty,oneof=ollama openai"`
+	EmbeddingModel                *string                      `yaml:"embeddingModel" validate:"omitempty"`
+	EmbeddingApiKey               *string                      `yaml:"embeddingApiKey" validate:"omitempty"`
+	WorkerLeaseDuration           *time.Duration               `yaml:"workerLeaseDuration" validate:"omitempty"`
+	WorkerMaxAttempt              *int32                       `yaml:"workerMaxAttempt" validate:"omitempty"`
+	WorkerDrainDuration           *time.Duration               `yaml:"workerDrainDuration" validate:"omitempty"`
+	WorkerExtractTimeout          *time.Duration               `yaml:"workerExtractTimeout" validate:"omitempty"`
+	WorkerTokenCountTimeout       *time.Duration               `yaml:"workerTokenCountTimeout" validate:"omitempty"`
+	WorkerEmbeddingTimeout        *time.Duration               `yaml:"workerEmbeddingTimeout" validate:"omitempty"`
+	WorkerQdrantTimeout           *time.Duration               `yaml:"workerQdrantTimeout" validate:"omitempty"`
+	WorkerPollInterval            *time.Duration               `yaml:"workerPollInterval" validate:"omitempty"`
+	WorkerEmbeddingBatchSize      *int                         `yaml:"workerEmbeddingBatchSize" validate:"omitempty,gte=1"`
+	WorkerExtractCapacity         *int                         `yaml:"workerExtractCapacity" validate:"omitempty,gte=1"`
+	WorkerExtractCircuitThreshold *int                         `yaml:"workerExtractCircuitThreshold" validate:"omitempty,gte=1"`
+	WorkerExtractCircuitCooldown  *time.Duration               `yaml:"workerExtractCircuitCooldown" validate:"omitempty"`
+	WorkerHostConcurrency         *int32                       `yaml:"workerHostConcurrency" validate:"omitempty,gte=1"`
+	WorkerHostRate                *int32                       `yaml:"workerHostRate" validate:"omitempty,gte=1"`
+	WorkerHosts                   map[string]*politeness.Limit `yaml:"workerHosts" validate:"omitempty,dive"`
+	WorkerRobots                  *bool                        `yaml:"workerRobots" validate:"omitempty"`
+	WorkerRobotsUserAgent         *string                      `yaml:"workerRobotsUserAgent" validate:"omitempty"`
+	WorkerNativeFallback          *bool                        `yaml:"workerNativeFallback" validate:"omitempty"`
+	WorkerNativeDomains           []*string                    `yaml:"workerNativeDomains" validate:"omitempty"`
+	WorkerPdf                     *bool                        `yaml:"workerPdf" validate:"omitempty"`
+	WorkerOcrModel                *string                      `yaml:"workerOcrModel" validate:"omitempty"`
+	WorkerOcrConcurrency          *int                         `yaml:"workerOcrConcurrency" validate:"omitempty,gte=1"`
+	WorkerOcrMinTextLength        *int                         `yaml:"workerOcrMinTextLength" validate:"omitempty,gte=0"`
+	WorkerClassify                *bool                        `yaml:"workerClassify" validate:"omitempty"`
+	WorkerClassifyProvider        *string                      `yaml:"workerClassifyProvider" validate:"omitempty,oneof=ollama openai"`
+	WorkerClassifyTextLength      *int                         `yaml:"workerClassifyTextLength" validate:"omitempty,gte=1"`
+	WorkerClassifyAutoThreshold   *float64                     `yaml:"workerClassifyAutoThreshold" validate:"omitempty,gt=0,lte=1"`
+	WorkerTitleGenerate           *bool                        `yaml:"workerTitleGenerate" validate:"omitempty"`
+	WorkerTitleProvider           *string                      `yaml:"workerTitleProvider" validate:"omitempty,oneof=ollama openai"`
+	WorkerUpsertBatchSize         *int                         `yaml:"workerUpsertBatchSize" validate:"omitempty,gte=1"`
+	WorkerMetricListen            *string                      `yaml:"workerMetricListen" validate:"omitempty"`
+	WorkerStages                  map[string]*pipeline.Stage   `yaml:"workerStages" validate:"omitempty,dive"`
+	Chunker                       *chunker.Strategy            `yaml:"chunker" validate:"omitempty"`
+	Dedup                         *dedup.Policy                `yaml:"dedup" validate:"omitempty"`
+	DedupCategories               map[string]*dedup.Policy     `yaml:"dedupCategories" validate:"omitempty,dive"`
+	Pii                           *pii.Policy                  `yaml:"pii" validate:"omitempty"`
+	PiiCategories                 map[string]*pii.Policy       `yaml:"piiCategories" validate:"omitempty,dive"`
+	Quality                       *quality.Policy              `yaml:"quality" validate:"omitempty"`
 }
 
 func Init() *Config {
@@ -60,6 +111,135 @@ func Init() *Config {
 		gut.Fatal("Invalid configuration", err)
 	}
 
+	// * apply default values
+	if config.WebMetricListen == nil {
+		config.WebMetricListen = gut.Ptr(":9090")
+	}
+	if config.EmbeddingProvider == nil {
+		config.EmbeddingProvider = gut.Ptr("ollama")
+	}
+	if config.EmbeddingModel == nil {
+		config.EmbeddingModel = config.OllamaEmbeddingModel
+	}
+	if config.WorkerLeaseDuration == nil {
+		config.WorkerLeaseDuration = gut.Ptr(5 * time.Minute)
+	}
+	if config.WorkerMaxAttempt == nil {
+		config.WorkerMaxAttempt = gut.Ptr(int32(3))
+	}
+	if config.WorkerDrainDuration == nil {
+		config.WorkerDrainDuration = gut.Ptr(30 * time.Second)
+	}
+	if config.WorkerExtractTimeout == nil {
+		config.WorkerExtractTimeout = gut.Ptr(10 * time.Minute)
+	}
+	if config.WorkerTokenCountTimeout == nil {
+		config.WorkerTokenCountTimeout = gut.Ptr(1 * time.Minute)
+	}
+	if config.WorkerEmbeddingTimeout == nil {
+		config.WorkerEmbeddingTimeout = gut.Ptr(1 * time.Minute)
+	}
+	if config.WorkerQdrantTimeout == nil {
+		config.WorkerQdrantTimeout = gut.Ptr(30 * time.Second)
+	}
+	if config.WorkerPollInterval == nil {
+		config.WorkerPollInterval = gut.Ptr(30 * time.Second)
+	}
+	if config.WorkerEmbeddingBatchSize == nil {
+		config.WorkerEmbeddingBatchSize = gut.Ptr(16)
+	}
+	if config.WorkerUpsertBatchSize == nil {
+		config.WorkerUpsertBatchSize = gut.Ptr(64)
+	}
+	if config.WorkerMetricListen == nil {
+		config.WorkerMetricListen = gut.Ptr(":9100")
+	}
+	if config.WorkerExtractCapacity == nil {
+		config.WorkerExtractCapacity = gut.Ptr(2)
+	}
+	if config.WorkerExtractCircuitThreshold == nil {
+		config.WorkerExtractCircuitThreshold = gut.Ptr(5)
+	}
+	if config.WorkerExtractCircuitCooldown == nil {
+		config.WorkerExtractCircuitCooldown = gut.Ptr(30 * time.Second)
+	}
+	if config.WorkerHostConcurrency == nil {
+		config.WorkerHostConcurrency = gut.Ptr(int32(2))
+	}
+	if config.WorkerHostRate == nil {
+		config.WorkerHostRate = gut.Ptr(int32(60))
+	}
+	if config.WorkerRobots == nil {
+		config.WorkerRobots = gut.Ptr(false)
+	}
+	if config.WorkerRobotsUserAgent == nil {
+		config.WorkerRobotsUserAgent = gut.Ptr("crawler")
+	}
+	if config.WorkerNativeFallback == nil {
+		config.WorkerNativeFallback = gut.Ptr(false)
+	}
+	if config.WorkerPdf == nil {
+		config.WorkerPdf = gut.Ptr(true)
+	}
+	if config.WorkerOcrModel == nil {
+		config.WorkerOcrModel = config.OpenaiModel
+	}
+	if config.WorkerOcrConcurrency == nil {
+		config.WorkerOcrConcurrency = gut.Ptr(4)
+	}
+	if config.WorkerOcrMinTextLength == nil {
+		config.WorkerOcrMinTextLength = gut.Ptr(512)
+	}
+	if config.WorkerClassify == nil {
+		config.WorkerClassify = gut.Ptr(false)
+	}
+	if config.WorkerClassifyProvider == nil {
+		config.WorkerClassifyProvider = gut.Ptr("ollama")
+	}
+	if config.WorkerClassifyTextLength == nil {
+		config.WorkerClassifyTextLength = gut.Ptr(2000)
+	}
+	if config.WorkerTitleGenerate == nil {
+		config.WorkerTitleGenerate = gut.Ptr(false)
+	}
+	if config.WorkerTitleProvider == nil {
+		config.WorkerTitleProvider = gut.Ptr("ollama")
+	}
+	if config.Chunker == nil {
+		// * sizes in characters, which rarely exceed one token each, keep chunks within embedding model context
+		// * token strategy is opt-in since tiktoken encodings only approximate embedding model tokenizer
+		config.Chunker = &chunker.Strategy{
+			Name:     gut.Ptr(chunker.StrategyRecursive),
+			Size:     gut.Ptr(1024),
+			Overlap:  gut.Ptr(128),
+			Encoding: nil,
+		}
+	}
+	config.Dedup = (&dedup.Policy{
+		Threshold: gut.Ptr(0.975),
+		Ratio:     gut.Ptr(2.0 / 3),
+		Scope:     gut.Ptr(dedup.ScopeType),
+	}).Merge(config.Dedup)
+	config.Pii = (&pii.Policy{
+		Enabled: gut.Ptr(true),
+		Types: []*string{
+			gut.Ptr(pii.TypeEmail),
+			gut.Ptr(pii.TypePhone),
+			gut.Ptr(pii.TypeNationalId),
+			gut.Ptr(pii.TypeCreditCard),
+			gut.Ptr(pii.TypeIp),
+		},
+	}).Merge(config.Pii)
+	config.Quality = (&quality.Policy{
+		Action:               gut.Ptr(quality.ActionOff),
+		MinLength:            gut.Ptr(200),
+		MaxSymbolRatio:       gut.Ptr(0.3),
+		MaxRepeatedLineRatio: gut.Ptr(0.5),
+		MinStopWordRatio:     gut.Ptr(0.05),
+		MinThaiRatio:         nil,
+		MinMeanLineLength:    nil,
+	}).Merge(config.Quality)
+
 	// * apply secret key
 	var bytes = []byte(*config.Secret)
 	if len(bytes) < 16 {
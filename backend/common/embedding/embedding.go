@@ -0,0 +1,27 @@
+package embedding
+
+import (
+	"backend/common/config"
+	"context"
+	"fmt"
+)
+
+const (
+	ProviderOllama = "ollama"
+	ProviderOpenai = "openai"
+)
+
+type Embedder interface {
+	Embed(ctx context.Context, inputs []string) ([][]float32, error)
+}
+
+func Init(config *config.Config) (Embedder, error) {
+	switch *config.EmbeddingProvider {
+	case ProviderOllama:
+		return NewOllama(config)
+	case ProviderOpenai:
+		return NewOpenai(config)
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %s", *config.EmbeddingProvider)
+	}
+}
@@ -0,0 +1,52 @@
+package embedding
+
+import (
+	"backend/common/config"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/bsthun/gut"
+	"github.com/ollama/ollama/api"
+)
+
+type Ollama struct {
+	client *api.Client
+	model  string
+}
+
+func NewOllama(config *config.Config) (*Ollama, error) {
+	baseUrl, err := url.Parse(*config.OllamaBaseUrl)
+	if err != nil {
+		return nil, gut.Err(false, "failed to parse ollama url", err)
+	}
+
+	// * request timeout follows embedding timeout, callers may shorten it by context
+	httpClient := &http.Client{
+		Timeout: *config.WorkerEmbeddingTimeout,
+	}
+
+	return &Ollama{
+		client: api.NewClient(baseUrl, httpClient),
+		model:  *config.EmbeddingModel,
+	}, nil
+}
+
+func (r *Ollama) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	resp, err := r.client.Embed(ctx, &api.EmbedRequest{
+		Model:     r.model,
+		Input:     inputs,
+		KeepAlive: nil,
+		Truncate:  nil,
+		Options:   nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Embeddings) != len(inputs) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(inputs), len(resp.Embeddings))
+	}
+
+	return resp.Embeddings, nil
+}
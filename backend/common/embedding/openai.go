@@ -0,0 +1,63 @@
+package embedding
+
+import (
+	"backend/common/config"
+	oai "backend/common/openai"
+	"context"
+	"fmt"
+
+	"github.com/openai/openai-go"
+)
+
+type Openai struct {
+	client *openai.Client
+	model  string
+}
+
+func NewOpenai(config *config.Config) (*Openai, error) {
+	if config.EndpointEmbedding == nil {
+		return nil, fmt.Errorf("openai embedding provider requires endpointEmbedding")
+	}
+
+	// * api key is optional for self-hosted servers
+	apiKey := ""
+	if config.EmbeddingApiKey != nil {
+		apiKey = *config.EmbeddingApiKey
+	}
+
+	return &Openai{
+		client: oai.New(*config.EndpointEmbedding, apiKey, *config.WorkerEmbeddingTimeout),
+		model:  *config.EmbeddingModel,
+	}, nil
+}
+
+func (r *Openai) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	resp, err := r.client.Embeddings.New(ctx, openai.EmbeddingNewParams{
+		Input: openai.EmbeddingNewParamsInputUnion{
+			OfArrayOfStrings: inputs,
+		},
+		Model:          r.model,
+		EncodingFormat: openai.EmbeddingNewParamsEncodingFormatFloat,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) != len(inputs) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(inputs), len(resp.Data))
+	}
+
+	// * place embeddings by index, servers are not required to keep input order
+	embeddings := make([][]float32, len(inputs))
+	for _, data := range resp.Data {
+		if data.Index < 0 || int(data.Index) >= len(inputs) {
+			return nil, fmt.Errorf("embedding index %d out of range", data.Index)
+		}
+		embedding := make([]float32, len(data.Embedding))
+		for i, value := range data.Embedding {
+			embedding[i] = float32(value)
+		}
+		embeddings[data.Index] = embedding
+	}
+
+	return embeddings, nil
+}
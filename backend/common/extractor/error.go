@@ -0,0 +1,37 @@
+package extractor
+
+import (
+	"fmt"
+)
+
+type UnsupportedError struct {
+	Type string
+}
+
+func (r *UnsupportedError) Error() string {
+	return fmt.Sprintf("unsupported task type %s", r.Type)
+}
+
+type NetworkError struct {
+	Err error
+}
+
+func (r *NetworkError) Error() string {
+	return fmt.Sprintf("extraction error: %v", r.Err)
+}
+
+func (r *NetworkError) Unwrap() error {
+	return r.Err
+}
+
+type ResponseError struct {
+	StatusCode int
+	Message    string
+}
+
+func (r *ResponseError) Error() string {
+	if r.StatusCode >= 500 {
+		return fmt.Sprintf("extraction %d (%s)", r.StatusCode, r.Message)
+	}
+	return fmt.Sprintf("extraction: %s", r.Message)
+}
@@ -0,0 +1,85 @@
+package extractor
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func Extract(ctx context.Context, extractor Extractor, pool *Pool, source string) (*Result, error) {
+	retry := extractor.Retry()
+	attempt := 0
+
+	for {
+		attempt++
+
+		// * acquire healthy endpoint, retries may land on another endpoint
+		base, err := pool.Acquire(ctx)
+		if err != nil {
+			return nil, &NetworkError{
+				Err: err,
+			}
+		}
+		endpoint, err := extractor.Endpoint(base.Base())
+		if err != nil {
+			pool.Return(base)
+			return nil, err
+		}
+
+		// * call extraction service
+		start := time.Now()
+		resp, err := resty.New().R().
+			SetContext(ctx).
+			SetHeader("Content-Type", "application/json").
+			SetBody(extractor.Request(source)).
+			Post(endpoint)
+		if err != nil {
+			err = &NetworkError{
+				Err: err,
+			}
+			pool.Release(base, time.Since(start), err)
+
+			// * network error is retried on another endpoint unless aborted
+			if ctx.Err() == nil && attempt < retry.Attempt {
+				continue
+			}
+			return nil, err
+		}
+
+		// * map response
+		result, err := extractor.Response(resp.StatusCode(), resp.Body())
+		pool.Release(base, time.Since(start), err)
+		if err != nil {
+			retryable := resp.StatusCode() >= 500 || retry.ClientError
+			if retryable && attempt < retry.Attempt {
+				if !sleep(ctx, retry.Delay) {
+					return nil, &NetworkError{
+						Err: ctx.Err(),
+					}
+				}
+				continue
+			}
+			return nil, err
+		}
+
+		// * re-extract once on suspiciously short text
+		if attempt == 1 && len(result.Text) < retry.MinTextLength {
+			continue
+		}
+
+		return result, nil
+	}
+}
+
+func sleep(ctx context.Context, duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
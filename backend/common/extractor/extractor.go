@@ -0,0 +1,109 @@
+package extractor
+
+import (
+	"backend/common/config"
+	"backend/util/metadata"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	ExtractorRemote = "remote"
+	ExtractorNative = "native"
+	ExtractorPdf    = "pdf"
+)
+
+type Extractor interface {
+	Type() string
+	Endpoint(base string) (string, error)
+	Request(source string) any
+	Response(statusCode int, body []byte) (*Result, error)
+	Retry() *Retry
+}
+
+type Result struct {
+	Title     string             `json:"title"`
+	Text      string             `json:"text"`
+	Extractor string             `json:"-"`
+	OcrPages  []int32            `json:"-"`
+	Metadata  *metadata.Metadata `json:"-"`
+}
+
+type Retry struct {
+	Attempt       int
+	Delay         time.Duration
+	ClientError   bool
+	MinTextLength int
+}
+
+type Registry struct {
+	extractors     map[string]Extractor
+	native         *Native
+	nativeFallback bool
+}
+
+func Init(config *config.Config) *Registry {
+	registry := &Registry{
+		extractors:     make(map[string]Extractor),
+		native:         NewNative(config),
+		nativeFallback: *config.WorkerNativeFallback,
+	}
+
+	// * built-in extraction service types
+	registry.Register(NewRemote("web", *config.EndpointWebPath))
+	registry.Register(NewRemote("doc", *config.EndpointDocPath))
+	registry.Register(NewRemote("youtube", *config.EndpointYoutubePath))
+
+	// * additional extraction service types from config
+	for taskType, path := range config.EndpointPaths {
+		registry.Register(NewRemote(taskType, *path))
+	}
+
+	return registry
+}
+
+func (r *Registry) Register(extractor Extractor) {
+	r.extractors[extractor.Type()] = extractor
+}
+
+func (r *Registry) Get(taskType string) (Extractor, bool) {
+	extractor, ok := r.extractors[taskType]
+	return extractor, ok
+}
+
+func (r *Registry) Types() []string {
+	types := make([]string, 0, len(r.extractors))
+	for taskType := range r.extractors {
+		types = append(types, taskType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func (r *Registry) Extract(ctx context.Context, taskType string, pool *Pool, source string) (*Result, error) {
+	extractor, ok := r.Get(taskType)
+	if !ok {
+		return nil, &UnsupportedError{
+			Type: taskType,
+		}
+	}
+
+	// * native extractor is primary for configured web domains
+	if taskType == "web" && r.native.Primary(source) {
+		return r.native.Extract(ctx, source)
+	}
+
+	result, err := Extract(ctx, extractor, pool, source)
+	if err != nil && taskType == "web" && r.nativeFallback && ctx.Err() == nil {
+		// * fallback to native extractor when extraction service gives up
+		nativeResult, nativeErr := r.native.Extract(ctx, source)
+		if nativeErr != nil {
+			return nil, fmt.Errorf("%w, native fallback: %v", err, nativeErr)
+		}
+		return nativeResult, nil
+	}
+
+	return result, err
+}
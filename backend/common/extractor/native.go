@@ -0,0 +1,120 @@
+package extractor
+
+import (
+	"backend/common/config"
+	"backend/util/network"
+	"backend/util/readability"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+type Native struct {
+	client    *http.Client
+	userAgent string
+	domains   []string
+}
+
+func NewNative(config *config.Config) *Native {
+	domains := make([]string, 0, len(config.WorkerNativeDomains))
+	for _, domain := range config.WorkerNativeDomains {
+		domains = append(domains, strings.TrimPrefix(strings.ToLower(*domain), "www."))
+	}
+
+	return &Native{
+		// * requests are bounded by extraction context and restricted to public addresses
+		client:    network.NewPublicClient(),
+		userAgent: *config.WorkerRobotsUserAgent,
+		domains:   domains,
+	}
+}
+
+func (r *Native) Primary(source string) bool {
+	parsed, err := url.Parse(source)
+	if err != nil {
+		return false
+	}
+
+	// * match configured domain and its subdomains
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	for _, domain := range r.domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Native) Extract(ctx context.Context, source string) (*Result, error) {
+	// * fetch source page
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, &ResponseError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("invalid source %v", err),
+		}
+	}
+	request.Header.Set("User-Agent", r.userAgent)
+	request.Header.Set("Accept", "text/html,application/xhtml+xml")
+	response, err := r.client.Do(request)
+	if err != nil {
+		return nil, &NetworkError{
+			Err: err,
+		}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 400 {
+		return nil, &ResponseError{
+			StatusCode: response.StatusCode,
+			Message:    fmt.Sprintf("source responded %s", response.Status),
+		}
+	}
+
+	// * only html documents are handled natively
+	contentType := response.Header.Get("Content-Type")
+	if contentType != "" {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+			return nil, &ResponseError{
+				StatusCode: response.StatusCode,
+				Message:    fmt.Sprintf("unsupported content type %s", mediaType),
+			}
+		}
+	}
+
+	// * decode declared charset and detect main content
+	reader, err := charset.NewReader(io.LimitReader(response.Body, 16<<20), contentType)
+	if err != nil {
+		return nil, &ResponseError{
+			StatusCode: response.StatusCode,
+			Message:    fmt.Sprintf("unsupported charset %v", err),
+		}
+	}
+	article, err := readability.Parse(reader)
+	if err != nil {
+		return nil, &NetworkError{
+			Err: err,
+		}
+	}
+	if strings.TrimSpace(article.Text) == "" {
+		return nil, &ResponseError{
+			StatusCode: response.StatusCode,
+			Message:    "no readable content",
+		}
+	}
+
+	return &Result{
+		Title:     article.Title,
+		Text:      article.Text,
+		Extractor: ExtractorNative,
+		Metadata:  article.Metadata,
+	}, nil
+}
@@ -0,0 +1,248 @@
+package extractor
+
+import (
+	"backend/common/config"
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+type Endpoint struct {
+	base                string
+	capacity            int
+	inflight            int
+	state               string
+	consecutiveFailures int
+	openedAt            time.Time
+	successCount        uint64
+	failureCount        uint64
+	latency             time.Duration
+	lastError           string
+}
+
+func (r *Endpoint) Base() string {
+	return r.base
+}
+
+type EndpointState struct {
+	Base                string
+	State               string
+	Capacity            int
+	Inflight            int
+	ConsecutiveFailures int
+	SuccessCount        uint64
+	FailureCount        uint64
+	Latency             time.Duration
+	OpenedAt            *time.Time
+	LastError           *string
+}
+
+type Pool struct {
+	mu        sync.Mutex
+	wake      chan struct{}
+	endpoints []*Endpoint
+	threshold int
+	cooldown  time.Duration
+}
+
+func NewPool(config *config.Config) *Pool {
+	pool := &Pool{
+		wake:      make(chan struct{}),
+		endpoints: make([]*Endpoint, 0, len(config.EndpointExtracts)),
+		threshold: *config.WorkerExtractCircuitThreshold,
+		cooldown:  *config.WorkerExtractCircuitCooldown,
+	}
+
+	for _, base := range config.EndpointExtracts {
+		// * resolve capacity of endpoint
+		capacity := *config.WorkerExtractCapacity
+		if override, ok := config.EndpointExtractCapacities[*base]; ok && override != nil {
+			capacity = *override
+		}
+
+		pool.endpoints = append(pool.endpoints, &Endpoint{
+			base:                *base,
+			capacity:            capacity,
+			inflight:            0,
+			state:               CircuitClosed,
+			consecutiveFailures: 0,
+			openedAt:            time.Time{},
+			successCount:        0,
+			failureCount:        0,
+			latency:             0,
+			lastError:           "",
+		})
+	}
+
+	return pool
+}
+
+func (r *Pool) Acquire(ctx context.Context) (*Endpoint, error) {
+	for {
+		r.mu.Lock()
+		endpoint := r.pick(time.Now())
+		if endpoint != nil {
+			endpoint.inflight++
+			r.mu.Unlock()
+			return endpoint, nil
+		}
+		wake := r.wake
+		r.mu.Unlock()
+
+		// * wait for released endpoint or cooldown of open circuit
+		timer := time.NewTimer(r.cooldown)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+func (r *Pool) pick(now time.Time) *Endpoint {
+	// * weight closed endpoints by free capacity
+	total := 0
+	for _, endpoint := range r.endpoints {
+		if endpoint.state == CircuitClosed && endpoint.inflight < endpoint.capacity {
+			total += endpoint.capacity - endpoint.inflight
+		}
+	}
+	if total > 0 {
+		n := rand.IntN(total)
+		for _, endpoint := range r.endpoints {
+			if endpoint.state != CircuitClosed || endpoint.inflight >= endpoint.capacity {
+				continue
+			}
+			n -= endpoint.capacity - endpoint.inflight
+			if n < 0 {
+				return endpoint
+			}
+		}
+	}
+
+	// * probe open endpoint for recovery after cooldown, one request at a time
+	for _, endpoint := range r.endpoints {
+		if endpoint.state == CircuitOpen && now.Sub(endpoint.openedAt) >= r.cooldown && endpoint.inflight == 0 {
+			endpoint.state = CircuitHalfOpen
+			return endpoint
+		}
+	}
+
+	return nil
+}
+
+func (r *Pool) Release(endpoint *Endpoint, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	endpoint.inflight--
+
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		// * aborted by caller, endpoint health is unknown
+		if endpoint.state == CircuitHalfOpen {
+			endpoint.state = CircuitOpen
+		}
+	case unhealthy(err):
+		endpoint.failureCount++
+		endpoint.consecutiveFailures++
+		endpoint.lastError = err.Error()
+		if endpoint.state == CircuitHalfOpen || endpoint.consecutiveFailures >= r.threshold {
+			endpoint.state = CircuitOpen
+			endpoint.openedAt = time.Now()
+		}
+	default:
+		endpoint.successCount++
+		endpoint.consecutiveFailures = 0
+		endpoint.state = CircuitClosed
+
+		// * exponentially weighted latency
+		if endpoint.latency == 0 {
+			endpoint.latency = latency
+		} else {
+			endpoint.latency = (endpoint.latency*4 + latency) / 5
+		}
+	}
+
+	r.broadcast()
+}
+
+func (r *Pool) Return(endpoint *Endpoint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// * release without health outcome
+	endpoint.inflight--
+	if endpoint.state == CircuitHalfOpen {
+		endpoint.state = CircuitOpen
+	}
+
+	r.broadcast()
+}
+
+func (r *Pool) broadcast() {
+	// * wake waiting acquirers
+	close(r.wake)
+	r.wake = make(chan struct{})
+}
+
+func (r *Pool) States() []*EndpointState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	states := make([]*EndpointState, 0, len(r.endpoints))
+	for _, endpoint := range r.endpoints {
+		state := &EndpointState{
+			Base:                endpoint.base,
+			State:               endpoint.state,
+			Capacity:            endpoint.capacity,
+			Inflight:            endpoint.inflight,
+			ConsecutiveFailures: endpoint.consecutiveFailures,
+			SuccessCount:        endpoint.successCount,
+			FailureCount:        endpoint.failureCount,
+			Latency:             endpoint.latency,
+			OpenedAt:            nil,
+			LastError:           nil,
+		}
+		if !endpoint.openedAt.IsZero() {
+			openedAt := endpoint.openedAt
+			state.OpenedAt = &openedAt
+		}
+		if endpoint.lastError != "" {
+			lastError := endpoint.lastError
+			state.LastError = &lastError
+		}
+		states = append(states, state)
+	}
+
+	return states
+}
+
+func unhealthy(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// * network errors and server errors count against endpoint, client errors are caused by source
+	var networkError *NetworkError
+	if errors.As(err, &networkError) {
+		return true
+	}
+	var responseError *ResponseError
+	if errors.As(err, &responseError) {
+		return responseError.StatusCode >= 500
+	}
+
+	return false
+}
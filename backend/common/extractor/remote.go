@@ -0,0 +1,93 @@
+package extractor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type Remote struct {
+	taskType string
+	path     string
+}
+
+type RemoteErrorResponse struct {
+	Detail any `json:"detail"`
+}
+
+func NewRemote(taskType string, path string) *Remote {
+	return &Remote{
+		taskType: taskType,
+		path:     path,
+	}
+}
+
+func (r *Remote) Type() string {
+	return r.taskType
+}
+
+func (r *Remote) Endpoint(base string) (string, error) {
+	return url.JoinPath(base, r.path)
+}
+
+func (r *Remote) Request(source string) any {
+	return map[string]string{
+		"url": source,
+	}
+}
+
+func (r *Remote) Response(statusCode int, body []byte) (*Result, error) {
+	// * handle server error
+	if statusCode >= 500 {
+		return nil, &ResponseError{
+			StatusCode: statusCode,
+			Message:    string(body),
+		}
+	}
+
+	// * handle client error
+	if statusCode >= 400 {
+		errorResp := new(RemoteErrorResponse)
+		_ = json.Unmarshal(body, errorResp)
+
+		var message string
+		if detail, ok := errorResp.Detail.(string); ok {
+			message = detail
+		} else if detail, ok := errorResp.Detail.(map[string]any); ok {
+			if msg, ok := detail["error"].(string); ok {
+				message = msg
+			} else {
+				message = fmt.Sprintf("%v", detail)
+			}
+		} else {
+			message = string(body)
+		}
+
+		return nil, &ResponseError{
+			StatusCode: statusCode,
+			Message:    message,
+		}
+	}
+
+	// * parse extracted result
+	result := new(Result)
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, &ResponseError{
+			StatusCode: statusCode,
+			Message:    fmt.Sprintf("invalid response %v", err),
+		}
+	}
+	result.Extractor = ExtractorRemote
+
+	return result, nil
+}
+
+func (r *Remote) Retry() *Retry {
+	return &Retry{
+		Attempt:       3,
+		Delay:         3 * time.Second,
+		ClientError:   true,
+		MinTextLength: 512,
+	}
+}
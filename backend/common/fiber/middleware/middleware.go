@@ -2,17 +2,20 @@ package middleware
 
 import (
 	"backend/common/config"
+	"backend/common/metric"
 	"backend/type/common"
 )
 
 type Middleware struct {
 	config   *config.Config
 	database common.Database
+	metric   *metric.Metric
 }
 
-func Init(config *config.Config, database common.Database) *Middleware {
+func Init(config *config.Config, database common.Database, metric *metric.Metric) *Middleware {
 	return &Middleware{
 		config:   config,
 		database: database,
+		metric:   metric,
 	}
 }
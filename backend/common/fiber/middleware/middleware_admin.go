@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"backend/type/common"
+	"github.com/bsthun/gut"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func (r *Middleware) Admin() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// * login claims
+		l := c.Locals("l").(*jwt.Token).Claims.(*common.LoginClaims)
+
+		// * reject non-admin user
+		user, err := r.database.P().UserGetById(c.Context(), l.UserId)
+		if err != nil {
+			return gut.Err(false, "failed to get user", err)
+		}
+		if !*user.IsAdmin {
+			return gut.Err(false, "admin permission required", nil)
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (r *Middleware) Metric() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		// * handle error in place to observe status written by error handler
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		// * label by registered route path to keep cardinality bounded
+		r.metric.HttpDuration.WithLabelValues(c.Method(), c.Route().Path, strconv.Itoa(c.Response().StatusCode())).Observe(time.Since(start).Seconds())
+
+		return nil
+	}
+}
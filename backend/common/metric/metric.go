@@ -0,0 +1,74 @@
+package metric
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type Metric struct {
+	registry           *prometheus.Registry
+	Claim              prometheus.Counter
+	StageDuration      *prometheus.HistogramVec
+	EmbeddingBatchSize prometheus.Histogram
+	TaskResult         *prometheus.CounterVec
+	Duplicate          *prometheus.CounterVec
+	HttpDuration       *prometheus.HistogramVec
+}
+
+func Init() *Metric {
+	metric := &Metric{
+		registry: prometheus.NewRegistry(),
+		Claim: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "task_claim_total",
+			Help: "Number of tasks claimed by worker.",
+		}),
+		StageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "task_stage_duration_seconds",
+			Help:    "Duration of worker pipeline stage.",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+		}, []string{"stage"}),
+		EmbeddingBatchSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "embedding_batch_size",
+			Help:    "Number of inputs in embedding request.",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 9),
+		}),
+		TaskResult: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "task_result_total",
+			Help: "Number of processed tasks by resulting status.",
+		}, []string{"status"}),
+		Duplicate: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "task_duplicate_total",
+			Help: "Number of tasks rejected as duplicate by kind of match.",
+		}, []string{"kind"}),
+		HttpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of http request by route.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+	}
+
+	// * register collectors
+	metric.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		metric.Claim,
+		metric.StageDuration,
+		metric.EmbeddingBatchSize,
+		metric.TaskResult,
+		metric.Duplicate,
+		metric.HttpDuration,
+	)
+
+	return metric
+}
+
+func (r *Metric) Register(collector prometheus.Collector) {
+	r.registry.MustRegister(collector)
+}
+
+func (r *Metric) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,46 @@
+package metric
+
+import (
+	"backend/type/common"
+	"context"
+	"time"
+
+	"github.com/bsthun/gut"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type Queue struct {
+	database common.Database
+	depth    *prometheus.Desc
+}
+
+func NewQueue(database common.Database) *Queue {
+	return &Queue{
+		database: database,
+		depth: prometheus.NewDesc(
+			"task_queue_depth",
+			"Number of tasks by status.",
+			[]string{"status"},
+			nil,
+		),
+	}
+}
+
+func (r *Queue) Describe(ch chan<- *prometheus.Desc) {
+	ch <- r.depth
+}
+
+func (r *Queue) Collect(ch chan<- prometheus.Metric) {
+	// * count tasks of each status on scrape
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	rows, err := r.database.P().TaskCountByStatus(ctx)
+	if err != nil {
+		gut.Debug("failed to count tasks by status: %v", err)
+		return
+	}
+
+	for _, row := range rows {
+		ch <- prometheus.MustNewConstMetric(r.depth, prometheus.GaugeValue, float64(*row.Count), *row.Status)
+	}
+}
@@ -0,0 +1,40 @@
+package metric
+
+import (
+	"backend/common/config"
+	"backend/type/common"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/bsthun/gut"
+	"go.uber.org/fx"
+)
+
+func Serve(lc fx.Lifecycle, config *config.Config, database common.Database, metric *Metric) {
+	// * queue depth is collected by api server only, workers would report same counts
+	metric.Register(NewQueue(database))
+
+	// * serve metrics on internal listener, separated from public app
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metric.Handler())
+	server := &http.Server{
+		Addr:    *config.WebMetricListen,
+		Handler: mux,
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					gut.Fatal("unable to listen metrics", err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			_ = server.Shutdown(ctx)
+			return nil
+		},
+	})
+}
@@ -9,10 +9,14 @@ import (
 )
 
 func Init(config *config.Config) *openai.Client {
+	return New(*config.OpenaiBaseUrl, *config.OpenaiApiKey, 60*time.Second)
+}
+
+func New(baseUrl string, apiKey string, timeout time.Duration) *openai.Client {
 	client := openai.NewClient(
-		option.WithBaseURL(*config.OpenaiBaseUrl),
-		option.WithAPIKey(*config.OpenaiApiKey),
-		option.WithRequestTimeout(60*time.Second),
+		option.WithBaseURL(baseUrl),
+		option.WithAPIKey(apiKey),
+		option.WithRequestTimeout(timeout),
 	)
 
 	return &client
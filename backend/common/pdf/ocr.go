@@ -0,0 +1,120 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image/jpeg"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gen2brain/go-fitz"
+	"github.com/openai/openai-go"
+)
+
+const ocrPrompt = "Output text content from this image, detects only English and Thai character, keeping original content and do not translate or modify the content. Remove and clean unnecessary spaces, line breaks, emojis, symbols, and characters. Remove line without meaningful context. Replace or merge any repeating dot or dashes into only '...'. This must revise arrangement or combine chunking of context to be meaningful content, ignore image or any non-text content, simplified markdown without any extra line break or separators. Can use extra formatting only '*' for bullet, markdown table, latex block for equations, otherwise will fallback to plain text. If no text, respond with '-'."
+
+func (r *Reader) ocr(ctx context.Context, doc *fitz.Document, pageNos []int32, texts []string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// * recognize pages with bounded concurrency, first error aborts remaining pages
+	semaphore := make(chan struct{}, r.concurrency)
+	wg := new(sync.WaitGroup)
+	mu := new(sync.Mutex)
+	var ocrErr error
+	for _, pageNo := range pageNos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-semaphore }()
+
+			text, err := r.page(ctx, doc, int(pageNo))
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if ocrErr == nil {
+					ocrErr = err
+					cancel()
+				}
+				return
+			}
+			texts[pageNo-1] = strings.TrimSpace(text)
+		}()
+	}
+	wg.Wait()
+
+	if ocrErr != nil {
+		return ocrErr
+	}
+	return ctx.Err()
+}
+
+func (r *Reader) page(ctx context.Context, doc *fitz.Document, pageNo int) (string, error) {
+	// * render page as jpeg image
+	img, err := doc.Image(pageNo - 1)
+	if err != nil {
+		return "", fmt.Errorf("failed to render page %d as image: %v", pageNo, err)
+	}
+	imgBuffer := new(bytes.Buffer)
+	if err := jpeg.Encode(imgBuffer, img, &jpeg.Options{Quality: 95}); err != nil {
+		return "", fmt.Errorf("failed to encode page image for page %d: %v", pageNo, err)
+	}
+	base64Image := base64.StdEncoding.EncodeToString(imgBuffer.Bytes())
+
+	// * prepare chat completion request params
+	chatParams := openai.ChatCompletionNewParams{
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(ocrPrompt),
+			{
+				OfUser: &openai.ChatCompletionUserMessageParam{
+					Role: "user",
+					Content: openai.ChatCompletionUserMessageParamContentUnion{
+						OfArrayOfContentParts: []openai.ChatCompletionContentPartUnionParam{
+							openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+								URL: fmt.Sprintf("data:image/jpeg;base64,%s", base64Image),
+							}),
+						},
+					},
+				},
+			},
+		},
+		Model:            r.model,
+		MaxTokens:        openai.Int(2048),
+		Temperature:      openai.Float(0.1),
+		TopP:             openai.Float(1),
+		FrequencyPenalty: openai.Float(0.5),
+		PresencePenalty:  openai.Float(0.2),
+	}
+
+	// * call openai api with retry
+	maxRetries := 3
+	for i := 0; ; i++ {
+		chatCompletion, err := r.openai.Chat.Completions.New(ctx, chatParams)
+		if err == nil && len(chatCompletion.Choices) > 0 {
+			return chatCompletion.Choices[0].Message.Content, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("empty completion")
+		}
+		if i == maxRetries-1 || ctx.Err() != nil {
+			return "", fmt.Errorf("failed to ocr page %d after %d retries: %v", pageNo, i+1, err)
+		}
+
+		timer := time.NewTimer(2 * time.Second)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
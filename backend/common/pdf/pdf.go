@@ -0,0 +1,194 @@
+package pdf
+
+import (
+	"backend/common/config"
+	"backend/util/metadata"
+	"backend/util/network"
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gen2brain/go-fitz"
+	"github.com/openai/openai-go"
+)
+
+var ErrNotPdf = errors.New("source is not a pdf document")
+
+var ErrTooLarge = errors.New("pdf document exceeds size limit")
+
+const sizeLimit = 64 << 20
+
+type Reader struct {
+	client        *http.Client
+	openai        *openai.Client
+	model         string
+	userAgent     string
+	concurrency   int
+	minTextLength int
+}
+
+type Document struct {
+	Text     string
+	OcrPages []int32
+	Metadata *metadata.Metadata
+}
+
+func New(config *config.Config, client *openai.Client) *Reader {
+	return &Reader{
+		// * requests are bounded by extraction context and restricted to public addresses
+		client:        network.NewPublicClient(),
+		openai:        client,
+		model:         *config.WorkerOcrModel,
+		userAgent:     *config.WorkerRobotsUserAgent,
+		concurrency:   *config.WorkerOcrConcurrency,
+		minTextLength: *config.WorkerOcrMinTextLength,
+	}
+}
+
+func (r *Reader) Fetch(ctx context.Context, source string) ([]byte, error) {
+	// * check declared content type before downloading document
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("User-Agent", r.userAgent)
+	response, err := r.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+
+	if response.StatusCode >= 400 {
+		return nil, fmt.Errorf("source responded %s", response.Status)
+	}
+	if !declared(response.Header.Get("Content-Type"), response.Request.URL.Path) {
+		return nil, ErrNotPdf
+	}
+	if response.ContentLength > sizeLimit {
+		return nil, ErrTooLarge
+	}
+
+	// * download pdf document
+	request, err = http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("User-Agent", r.userAgent)
+	response, err = r.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 400 {
+		return nil, fmt.Errorf("source responded %s", response.Status)
+	}
+
+	// * verify pdf by magic bytes, declared content type may be wrong
+	body := bufio.NewReaderSize(response.Body, 1024)
+	head, err := body.Peek(1024)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if !bytes.HasPrefix(bytes.TrimLeft(head, "\x00\t\n\r "), []byte("%PDF-")) {
+		return nil, ErrNotPdf
+	}
+
+	// * truncated document cannot be decoded, leave it to extraction service
+	data, err := io.ReadAll(io.LimitReader(body, sizeLimit+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > sizeLimit {
+		return nil, ErrTooLarge
+	}
+
+	return data, nil
+}
+
+func (r *Reader) Extract(ctx context.Context, data []byte) (*Document, error) {
+	doc, err := fitz.NewFromMemory(data)
+	if err != nil {
+		return nil, fmt.Errorf("pdf decode error: %v", err)
+	}
+	defer doc.Close()
+
+	// * read embedded text layer of each page
+	pageCount := doc.NumPage()
+	texts := make([]string, pageCount)
+	total := 0
+	for pageNo := 0; pageNo < pageCount; pageNo++ {
+		text, err := doc.Text(pageNo)
+		if err != nil {
+			text = ""
+		}
+		texts[pageNo] = strings.TrimSpace(text)
+		total += utf8.RuneCountInString(texts[pageNo])
+	}
+
+	// * escalate pages without usable text layer when document text is short or garbled
+	ocrPages := make([]int32, 0)
+	if total < r.minTextLength || garbled(strings.Join(texts, "\n")) {
+		for pageNo, text := range texts {
+			if utf8.RuneCountInString(text) < 32 || garbled(text) {
+				ocrPages = append(ocrPages, int32(pageNo+1))
+			}
+		}
+	}
+
+	if len(ocrPages) > 0 {
+		if err := r.ocr(ctx, doc, ocrPages, texts); err != nil {
+			return nil, err
+		}
+	}
+
+	// * join pages in order, skipping empty pages
+	pages := make([]string, 0, pageCount)
+	for _, text := range texts {
+		if text != "" && text != "-" {
+			pages = append(pages, text)
+		}
+	}
+
+	return &Document{
+		Text:     strings.Join(pages, "\n\n"),
+		OcrPages: ocrPages,
+		Metadata: metadata.FromPdf(doc.Metadata()),
+	}, nil
+}
+
+func declared(contentType string, path string) bool {
+	// * generic binary types are accepted only for pdf file names
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/pdf", "application/x-pdf":
+		return true
+	case "", "application/octet-stream", "binary/octet-stream":
+		return strings.HasSuffix(strings.ToLower(path), ".pdf")
+	}
+	return false
+}
+
+func garbled(text string) bool {
+	total := 0
+	invalid := 0
+	for _, char := range text {
+		if unicode.IsSpace(char) {
+			continue
+		}
+		total++
+		if char == utf8.RuneError || unicode.IsControl(char) || unicode.Is(unicode.Co, char) || !unicode.IsPrint(char) {
+			invalid++
+		}
+	}
+
+	return total > 0 && float64(invalid)/float64(total) > 0.1
+}
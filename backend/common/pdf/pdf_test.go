@@ -0,0 +1,28 @@
+package pdf
+
+import "testing"
+
+func TestDeclared(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		path        string
+		pdf         bool
+	}{
+		{name: "pdf type", contentType: "application/pdf", path: "/report", pdf: true},
+		{name: "pdf type with parameter", contentType: "application/pdf; charset=binary", path: "/report", pdf: true},
+		{name: "legacy pdf type", contentType: "application/x-pdf", path: "/report", pdf: true},
+		{name: "binary type with pdf name", contentType: "application/octet-stream", path: "/files/Report.PDF", pdf: true},
+		{name: "missing type with pdf name", contentType: "", path: "/report.pdf", pdf: true},
+		{name: "binary type without pdf name", contentType: "application/octet-stream", path: "/report.docx", pdf: false},
+		{name: "word document", contentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", path: "/report.pdf", pdf: false},
+		{name: "html page", contentType: "text/html; charset=utf-8", path: "/report", pdf: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if pdf := declared(test.contentType, test.path); pdf != test.pdf {
+				t.Fatalf("expected %v, got %v", test.pdf, pdf)
+			}
+		})
+	}
+}
@@ -3,7 +3,17 @@ SELECT *
 FROM categories
 WHERE name = $1;
 
+-- name: CategoryGetById :one
+SELECT *
+FROM categories
+WHERE id = $1;
+
 -- name: CategoryList :many
 SELECT *
 FROM categories
-ORDER BY name;
\ No newline at end of file
+ORDER BY name;
+
+-- name: CategoryUpdateWeight :exec
+UPDATE categories
+SET weight = $2
+WHERE id = $1;
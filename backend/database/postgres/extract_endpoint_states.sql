@@ -0,0 +1,25 @@
+-- name: ExtractEndpointStateUpsert :exec
+INSERT INTO extract_endpoint_states (worker_id, base, state, capacity, inflight, consecutive_failures, success_count, failure_count, latency, opened_at, last_error)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (worker_id, base) DO UPDATE
+SET state                = EXCLUDED.state,
+    capacity             = EXCLUDED.capacity,
+    inflight             = EXCLUDED.inflight,
+    consecutive_failures = EXCLUDED.consecutive_failures,
+    success_count        = EXCLUDED.success_count,
+    failure_count        = EXCLUDED.failure_count,
+    latency              = EXCLUDED.latency,
+    opened_at            = EXCLUDED.opened_at,
+    last_error           = EXCLUDED.last_error,
+    updated_at           = CURRENT_TIMESTAMP;
+
+-- name: ExtractEndpointStateList :many
+SELECT *
+FROM extract_endpoint_states
+WHERE updated_at >= sqlc.arg('since')::TIMESTAMP
+ORDER BY base, worker_id;
+
+-- name: ExtractEndpointStateDeleteStale :execrows
+DELETE
+FROM extract_endpoint_states
+WHERE updated_at < sqlc.arg('before')::TIMESTAMP;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks DROP CONSTRAINT tasks_type_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_type_check CHECK ( type ~ '^[a-z0-9_]+$' );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tasks DROP CONSTRAINT tasks_type_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_type_check CHECK ( type IN ('web', 'doc', 'youtube') );
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE task_stats
+(
+    id                   BIGSERIAL PRIMARY KEY,
+    task_id              BIGINT    REFERENCES tasks (id) ON DELETE CASCADE NOT NULL UNIQUE,
+    started_at           TIMESTAMP                                        NOT NULL,
+    finished_at          TIMESTAMP                                        NOT NULL,
+    extract_duration     BIGINT                                           NULL,
+    token_count_duration BIGINT                                           NULL,
+    split_duration       BIGINT                                           NULL,
+    embedding_duration   BIGINT                                           NULL,
+    search_duration      BIGINT                                           NULL,
+    upsert_duration      BIGINT                                           NULL,
+    chunk_count          INTEGER                                          NOT NULL DEFAULT 0,
+    detail               JSONB                                            NOT NULL DEFAULT '{}',
+    created_at           TIMESTAMP                                        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at           TIMESTAMP                                        NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER auto_updated_at_task_stats
+    BEFORE UPDATE
+    ON task_stats
+    FOR EACH ROW
+EXECUTE FUNCTION auto_updated_at();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE task_stats;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks ADD COLUMN claimed_by VARCHAR(255) NULL;
+ALTER TABLE tasks ADD COLUMN lease_expires_at TIMESTAMP NULL;
+ALTER TABLE tasks ADD COLUMN attempt INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX idx_tasks_status_lease_expires_at ON tasks (status, lease_expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_tasks_status_lease_expires_at;
+ALTER TABLE tasks DROP COLUMN attempt;
+ALTER TABLE tasks DROP COLUMN lease_expires_at;
+ALTER TABLE tasks DROP COLUMN claimed_by;
+-- +goose StatementEnd
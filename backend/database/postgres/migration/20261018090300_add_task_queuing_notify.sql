@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- * notify workers when a task enters the queue, constant payload collapses notifications within a transaction
+CREATE OR REPLACE FUNCTION notify_tasks_queuing()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM pg_notify('tasks_queuing', '');
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER notify_tasks_queuing_insert
+    AFTER INSERT
+    ON tasks
+    FOR EACH ROW
+    WHEN ( NEW.status = 'queuing' )
+EXECUTE FUNCTION notify_tasks_queuing();
+
+CREATE TRIGGER notify_tasks_queuing_update
+    AFTER UPDATE OF status
+    ON tasks
+    FOR EACH ROW
+    WHEN ( NEW.status = 'queuing' AND OLD.status IS DISTINCT FROM NEW.status )
+EXECUTE FUNCTION notify_tasks_queuing();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER notify_tasks_queuing_update ON tasks;
+DROP TRIGGER notify_tasks_queuing_insert ON tasks;
+DROP FUNCTION notify_tasks_queuing;
+-- +goose StatementEnd
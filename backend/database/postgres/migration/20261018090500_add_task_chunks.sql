@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE task_chunks
+(
+    id                 BIGSERIAL PRIMARY KEY,
+    task_id            BIGINT           REFERENCES tasks (id) ON DELETE CASCADE NOT NULL,
+    chunk_no           INTEGER                                                  NOT NULL,
+    start_offset       INTEGER                                                  NULL,
+    end_offset         INTEGER                                                  NULL,
+    token_count        INTEGER                                                  NULL,
+    hash               VARCHAR(64)                                              NOT NULL,
+    point_id           VARCHAR(36)                                              NULL,
+    duplicate_task_id  BIGINT           REFERENCES tasks (id) ON DELETE SET NULL NULL,
+    duplicate_chunk_no INTEGER                                                  NULL,
+    duplicate_score    DOUBLE PRECISION                                         NULL,
+    created_at         TIMESTAMP                                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at         TIMESTAMP                                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (task_id, chunk_no)
+);
+
+CREATE INDEX idx_task_chunks_hash ON task_chunks (hash);
+
+CREATE TRIGGER auto_updated_at_task_chunks
+    BEFORE UPDATE
+    ON task_chunks
+    FOR EACH ROW
+EXECUTE FUNCTION auto_updated_at();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE task_chunks;
+-- +goose StatementEnd
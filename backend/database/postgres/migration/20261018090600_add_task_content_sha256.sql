@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks
+    ADD COLUMN content_sha256 VARCHAR(64) NULL;
+
+UPDATE tasks
+SET content_sha256 = encode(sha256(convert_to(btrim(regexp_replace(content, '[ \t\n\r\f\v]+', ' ', 'g'), ' '), 'UTF8')), 'hex')
+WHERE content IS NOT NULL
+  AND content <> '';
+
+CREATE INDEX idx_tasks_content_sha256 ON tasks (content_sha256);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_tasks_content_sha256;
+
+ALTER TABLE tasks
+    DROP COLUMN content_sha256;
+-- +goose StatementEnd
@@ -0,0 +1,81 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks
+    ADD COLUMN canonical_source TEXT NULL;
+
+-- * compute canonical source of legacy url tasks, mirroring canonical.Url except for query escaping
+CREATE TEMPORARY TABLE task_canonical_sources ON COMMIT DROP AS
+WITH parsed AS (
+    SELECT id,
+           regexp_replace(regexp_replace(rtrim(lower(parts[1]), '.'), '^www\.', ''), ':(80|443)$', '') AS host,
+           COALESCE(NULLIF(rtrim(parts[2], '/'), ''), '/') AS path,
+           COALESCE(parts[3], '') AS query
+    FROM (
+        SELECT id,
+               regexp_match(trim(source), '^[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^@/?#]*@)?([^/?#]+)([^?#]*)(?:\?([^#]*))?') AS parts
+        FROM tasks
+        WHERE is_raw = false
+    ) matched
+    WHERE parts IS NOT NULL
+), params AS (
+    SELECT parsed.id,
+           string_agg(param.value, '&' ORDER BY split_part(param.value, '=', 1), param.no) AS query
+    FROM parsed,
+         unnest(string_to_array(parsed.query, '&')) WITH ORDINALITY AS param(value, no)
+    WHERE param.value <> ''
+      AND lower(split_part(param.value, '=', 1)) NOT LIKE 'utm\_%'
+      AND lower(split_part(param.value, '=', 1)) NOT IN ('fbclid', 'gclid', 'dclid', 'gbraid', 'wbraid', 'msclkid', 'yclid', 'igshid', 'mc_cid', 'mc_eid', 'ref_src', '_ga', '_gl', 'si', 'feature')
+    GROUP BY parsed.id
+)
+SELECT parsed.id,
+       COALESCE(
+           CASE WHEN parsed.host = 'youtu.be'
+               THEN 'https://youtube.com/watch?v=' || substring(parsed.path from '^/([A-Za-z0-9_-]{11})(?:/|$)') END,
+           CASE WHEN parsed.host IN ('youtube.com', 'm.youtube.com', 'music.youtube.com', 'youtube-nocookie.com')
+               THEN 'https://youtube.com/watch?v=' || COALESCE(
+                   CASE WHEN parsed.path ~ '^/watch(/|$)' THEN substring(parsed.query from '(?:^|&)v=([A-Za-z0-9_-]{11})(?:&|$)') END,
+                   substring(parsed.path from '^/(?:shorts|embed|live|v)/([A-Za-z0-9_-]{11})(?:/|$)')
+               ) END,
+           CASE WHEN parsed.host = 'drive.google.com'
+               THEN 'https://drive.google.com/file/d/' || COALESCE(
+                   substring(parsed.path from '^/file/d/([A-Za-z0-9_-]{10,})(?:/|$)'),
+                   substring(parsed.query from '(?:^|&)id=([A-Za-z0-9_-]{10,})(?:&|$)')
+               ) END,
+           CASE WHEN parsed.host = 'docs.google.com'
+               THEN 'https://docs.google.com/' || substring(parsed.path from '^/([^/]+/d/[A-Za-z0-9_-]{10,})(?:/|$)') END,
+           'https://' || parsed.host || parsed.path || COALESCE('?' || params.query, '')
+       ) AS canonical_source
+FROM parsed
+LEFT JOIN params ON params.id = parsed.id;
+
+-- * failed tasks never block resubmission, so they all keep their canonical source
+UPDATE tasks
+SET canonical_source = task_canonical_sources.canonical_source
+FROM task_canonical_sources
+WHERE tasks.id = task_canonical_sources.id
+  AND tasks.status = 'failed';
+
+-- * dedup remaining tasks, only first completed or oldest task of each source keeps canonical source
+UPDATE tasks
+SET canonical_source = ranked.canonical_source
+FROM (
+    SELECT task_canonical_sources.id,
+           task_canonical_sources.canonical_source,
+           row_number() OVER (PARTITION BY task_canonical_sources.canonical_source ORDER BY (tasks.status = 'completed') DESC, tasks.id) AS rank
+    FROM task_canonical_sources
+    JOIN tasks ON tasks.id = task_canonical_sources.id
+    WHERE tasks.status <> 'failed'
+) ranked
+WHERE tasks.id = ranked.id
+  AND ranked.rank = 1;
+
+CREATE UNIQUE INDEX idx_tasks_canonical_source ON tasks (canonical_source) WHERE status <> 'failed';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_tasks_canonical_source;
+
+ALTER TABLE tasks
+    DROP COLUMN canonical_source;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE task_duplicates
+(
+    id                   BIGSERIAL PRIMARY KEY,
+    task_id              BIGINT           REFERENCES tasks (id) ON DELETE CASCADE NOT NULL,
+    duplicate_of_task_id BIGINT           REFERENCES tasks (id) ON DELETE CASCADE NOT NULL,
+    chunk_no             INTEGER                                                  NULL,
+    score                DOUBLE PRECISION                                         NOT NULL,
+    created_at           TIMESTAMP                                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at           TIMESTAMP                                                NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_task_duplicates_task_id ON task_duplicates (task_id);
+CREATE INDEX idx_task_duplicates_duplicate_of_task_id ON task_duplicates (duplicate_of_task_id);
+
+CREATE TRIGGER auto_updated_at_task_duplicates
+    BEFORE UPDATE
+    ON task_duplicates
+    FOR EACH ROW
+EXECUTE FUNCTION auto_updated_at();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE task_duplicates;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE extract_endpoint_states
+(
+    id                   BIGSERIAL PRIMARY KEY,
+    worker_id            VARCHAR(255) NOT NULL,
+    base                 TEXT         NOT NULL,
+    state                VARCHAR(16)  NOT NULL,
+    capacity             INTEGER      NOT NULL,
+    inflight             INTEGER      NOT NULL,
+    consecutive_failures INTEGER      NOT NULL,
+    success_count        BIGINT       NOT NULL,
+    failure_count        BIGINT       NOT NULL,
+    latency              BIGINT       NOT NULL,
+    opened_at            TIMESTAMP    NULL,
+    last_error           TEXT         NULL,
+    created_at           TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at           TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (worker_id, base)
+);
+
+CREATE TRIGGER auto_updated_at_extract_endpoint_states
+    BEFORE UPDATE
+    ON extract_endpoint_states
+    FOR EACH ROW
+EXECUTE FUNCTION auto_updated_at();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE extract_endpoint_states;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks ADD COLUMN source_host VARCHAR(255) NULL;
+ALTER TABLE tasks ADD COLUMN claimed_at TIMESTAMP NULL;
+
+UPDATE tasks
+SET source_host = regexp_replace(lower(substring(source from '^[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^@/]*@)?([^/:?#]+)')), '^www\.', '')
+WHERE is_raw = false;
+
+CREATE INDEX idx_tasks_source_host_status ON tasks (source_host, status);
+CREATE INDEX idx_tasks_source_host_claimed_at ON tasks (source_host, claimed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_tasks_source_host_claimed_at;
+DROP INDEX idx_tasks_source_host_status;
+
+ALTER TABLE tasks DROP COLUMN claimed_at;
+ALTER TABLE tasks DROP COLUMN source_host;
+-- +goose StatementEnd
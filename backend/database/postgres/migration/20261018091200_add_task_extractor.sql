@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks ADD COLUMN extractor VARCHAR(32) NULL;
+
+UPDATE tasks
+SET extractor = 'remote'
+WHERE is_raw = false
+  AND content IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tasks DROP COLUMN extractor;
+-- +goose StatementEnd
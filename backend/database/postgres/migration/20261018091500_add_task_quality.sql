@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks ADD COLUMN quality JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE tasks DROP CONSTRAINT tasks_status_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_status_check CHECK ( status IN ('queuing', 'processing', 'completed', 'failed', 'ignored', 'low_quality') );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE tasks SET status = 'failed' WHERE status = 'low_quality';
+
+ALTER TABLE tasks DROP CONSTRAINT tasks_status_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_status_check CHECK ( status IN ('queuing', 'processing', 'completed', 'failed', 'ignored') );
+
+ALTER TABLE tasks DROP COLUMN quality;
+-- +goose StatementEnd
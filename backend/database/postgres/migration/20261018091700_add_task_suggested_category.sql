@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks ADD COLUMN suggested_category_id BIGINT REFERENCES categories (id) ON DELETE SET NULL NULL;
+ALTER TABLE tasks ADD COLUMN suggested_confidence DOUBLE PRECISION NULL;
+ALTER TABLE tasks ADD COLUMN submitted_category_id BIGINT REFERENCES categories (id) ON DELETE SET NULL NULL;
+
+CREATE INDEX idx_tasks_suggested_category_id ON tasks (suggested_category_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_tasks_suggested_category_id;
+
+ALTER TABLE tasks DROP COLUMN submitted_category_id;
+ALTER TABLE tasks DROP COLUMN suggested_confidence;
+ALTER TABLE tasks DROP COLUMN suggested_category_id;
+-- +goose StatementEnd
@@ -0,0 +1,94 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN weight DOUBLE PRECISION NOT NULL DEFAULT 1 CHECK ( weight > 0 );
+ALTER TABLE categories ADD COLUMN weight DOUBLE PRECISION NOT NULL DEFAULT 1 CHECK ( weight > 0 );
+
+-- * claim walks queued tasks of single flow without scanning whole queue
+CREATE INDEX idx_tasks_queuing ON tasks (user_id, priority, created_at) WHERE status = 'queuing';
+
+-- * one flow per user and priority, deficit is spent by claims and replenished by user weight each round
+CREATE TABLE task_queues
+(
+    user_id    BIGINT           REFERENCES users (id) ON DELETE CASCADE NOT NULL,
+    priority   INTEGER                                                  NOT NULL,
+    queued     INTEGER                                                  NOT NULL DEFAULT 0,
+    deficit    DOUBLE PRECISION                                         NOT NULL DEFAULT 0,
+    served_at  TIMESTAMP                                                NULL,
+    created_at TIMESTAMP                                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP                                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, priority)
+);
+
+CREATE INDEX idx_task_queues_active ON task_queues (priority, served_at) WHERE queued > 0;
+
+CREATE TRIGGER auto_updated_at_task_queues
+    BEFORE UPDATE
+    ON task_queues
+    FOR EACH ROW
+EXECUTE FUNCTION auto_updated_at();
+
+-- * keep queued count of flows, emptied flow forfeits its deficit
+CREATE OR REPLACE FUNCTION count_task_queues()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.status = 'queuing' AND OLD.user_id IS NOT NULL THEN
+        UPDATE task_queues
+        SET queued  = queued - 1,
+            deficit = CASE WHEN queued > 1 THEN deficit ELSE 0 END
+        WHERE user_id = OLD.user_id
+          AND priority = OLD.priority;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.status = 'queuing' AND NEW.user_id IS NOT NULL THEN
+        INSERT INTO task_queues (user_id, priority, queued)
+        VALUES (NEW.user_id, NEW.priority, 1)
+        ON CONFLICT (user_id, priority) DO UPDATE SET queued = task_queues.queued + 1;
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER count_task_queues_insert
+    AFTER INSERT
+    ON tasks
+    FOR EACH ROW
+    WHEN ( NEW.status = 'queuing' )
+EXECUTE FUNCTION count_task_queues();
+
+CREATE TRIGGER count_task_queues_update
+    AFTER UPDATE OF status, priority, user_id
+    ON tasks
+    FOR EACH ROW
+    WHEN ( (OLD.status = 'queuing' OR NEW.status = 'queuing') AND
+           (OLD.status, OLD.priority, OLD.user_id) IS DISTINCT FROM (NEW.status, NEW.priority, NEW.user_id) )
+EXECUTE FUNCTION count_task_queues();
+
+CREATE TRIGGER count_task_queues_delete
+    AFTER DELETE
+    ON tasks
+    FOR EACH ROW
+    WHEN ( OLD.status = 'queuing' )
+EXECUTE FUNCTION count_task_queues();
+
+-- * backfill flows of tasks already queued
+INSERT INTO task_queues (user_id, priority, queued)
+SELECT user_id, priority, COUNT(*)
+FROM tasks
+WHERE status = 'queuing'
+  AND user_id IS NOT NULL
+GROUP BY user_id, priority;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER count_task_queues_delete ON tasks;
+DROP TRIGGER count_task_queues_update ON tasks;
+DROP TRIGGER count_task_queues_insert ON tasks;
+DROP FUNCTION count_task_queues;
+DROP TABLE task_queues;
+DROP INDEX idx_tasks_queuing;
+ALTER TABLE categories DROP COLUMN weight;
+ALTER TABLE users DROP COLUMN weight;
+ALTER TABLE tasks DROP COLUMN priority;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- * backfill near duplicates from legacy "duplicate #id score%, ..." reasons, one row per matched chunk
+INSERT INTO task_duplicates (task_id, duplicate_of_task_id, chunk_no, score)
+SELECT tasks.id, matches.match[1]::BIGINT, NULL, matches.match[2]::DOUBLE PRECISION / 100
+FROM tasks,
+     regexp_matches(tasks.failed_reason, '#(\d+) (\d+(?:\.\d+)?)%', 'g') AS matches(match)
+WHERE tasks.status = 'failed'
+  AND tasks.failed_reason LIKE 'duplicate #%'
+  AND EXISTS (SELECT 1 FROM tasks originals WHERE originals.id = matches.match[1]::BIGINT)
+  AND NOT EXISTS (SELECT 1 FROM task_duplicates WHERE task_duplicates.task_id = tasks.id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- * backfilled rows are indistinguishable from recorded ones
+SELECT 1;
+-- +goose StatementEnd
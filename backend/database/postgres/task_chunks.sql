@@ -0,0 +1,43 @@
+-- name: TaskChunkUpsert :exec
+INSERT INTO task_chunks (task_id, chunk_no, start_offset, end_offset, token_count, hash, point_id, duplicate_task_id, duplicate_chunk_no, duplicate_score)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (task_id, chunk_no) DO UPDATE
+SET start_offset       = EXCLUDED.start_offset,
+    end_offset         = EXCLUDED.end_offset,
+    token_count        = EXCLUDED.token_count,
+    hash               = EXCLUDED.hash,
+    point_id           = EXCLUDED.point_id,
+    duplicate_task_id  = EXCLUDED.duplicate_task_id,
+    duplicate_chunk_no = EXCLUDED.duplicate_chunk_no,
+    duplicate_score    = EXCLUDED.duplicate_score;
+
+-- name: TaskChunkDeleteByTaskId :exec
+DELETE
+FROM task_chunks
+WHERE task_id = $1;
+
+-- name: TaskChunkDetachPoints :exec
+UPDATE task_chunks
+SET point_id = NULL
+WHERE task_id = $1;
+
+-- name: TaskChunkListByTaskId :many
+SELECT task_chunks.id,
+       task_chunks.chunk_no,
+       task_chunks.start_offset,
+       task_chunks.end_offset,
+       task_chunks.token_count,
+       task_chunks.hash,
+       task_chunks.point_id,
+       task_chunks.duplicate_task_id,
+       task_chunks.duplicate_chunk_no,
+       task_chunks.duplicate_score,
+       duplicate_tasks.title         as duplicate_title,
+       duplicate_tasks.status        as duplicate_status,
+       duplicate_chunks.start_offset as duplicate_start_offset,
+       duplicate_chunks.end_offset   as duplicate_end_offset
+FROM task_chunks
+LEFT JOIN tasks duplicate_tasks ON duplicate_tasks.id = task_chunks.duplicate_task_id
+LEFT JOIN task_chunks duplicate_chunks ON duplicate_chunks.task_id = task_chunks.duplicate_task_id AND duplicate_chunks.chunk_no = task_chunks.duplicate_chunk_no
+WHERE task_chunks.task_id = $1
+ORDER BY task_chunks.chunk_no;
@@ -0,0 +1,22 @@
+-- name: TaskDuplicateCreate :exec
+INSERT INTO task_duplicates (task_id, duplicate_of_task_id, chunk_no, score)
+VALUES ($1, $2, $3, $4);
+
+-- name: TaskDuplicateDeleteByTaskId :exec
+DELETE
+FROM task_duplicates
+WHERE task_id = $1;
+
+-- name: TaskDuplicateListByTaskId :many
+SELECT task_duplicates.id,
+       task_duplicates.duplicate_of_task_id,
+       task_duplicates.chunk_no,
+       task_duplicates.score,
+       duplicate_tasks.title  as duplicate_title,
+       duplicate_tasks.status as duplicate_status,
+       duplicate_tasks.user_id as duplicate_user_id,
+       task_duplicates.created_at
+FROM task_duplicates
+JOIN tasks duplicate_tasks ON duplicate_tasks.id = task_duplicates.duplicate_of_task_id
+WHERE task_duplicates.task_id = $1
+ORDER BY task_duplicates.chunk_no NULLS FIRST, task_duplicates.score DESC;
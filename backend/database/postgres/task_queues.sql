@@ -0,0 +1,40 @@
+-- name: TaskQueuePick :one
+SELECT *
+FROM task_queues
+WHERE queued > 0
+  AND NOT (user_id = ANY (sqlc.arg('skipped')::BIGINT[]))
+ORDER BY priority DESC, deficit > 0 DESC, served_at NULLS FIRST, user_id
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: TaskQueueReplenish :exec
+UPDATE task_queues
+SET deficit = task_queues.deficit + users.weight
+FROM users
+WHERE users.id = task_queues.user_id
+  AND task_queues.priority = $1
+  AND task_queues.queued > 0
+  AND task_queues.deficit <= 0;
+
+-- name: TaskQueueCharge :exec
+UPDATE task_queues
+SET deficit   = CASE
+                    WHEN queued > 0 THEN deficit - 1 / COALESCE((SELECT weight FROM categories WHERE id = sqlc.narg('category_id')), 1)
+                    ELSE 0
+                END,
+    served_at = NOW()
+WHERE user_id = sqlc.arg('user_id')
+  AND priority = sqlc.arg('priority');
+
+-- name: TaskQueueRotate :exec
+UPDATE task_queues
+SET served_at = NOW()
+WHERE user_id = $1
+  AND priority = $2;
+
+-- name: TaskQueueList :many
+SELECT task_queues.*, users.firstname, users.lastname, users.weight
+FROM task_queues
+JOIN users ON users.id = task_queues.user_id
+WHERE task_queues.queued > 0
+ORDER BY task_queues.priority DESC, task_queues.served_at NULLS FIRST;
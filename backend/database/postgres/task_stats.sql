@@ -0,0 +1,43 @@
+-- name: TaskStatUpsert :exec
+INSERT INTO task_stats (task_id, started_at, finished_at, extract_duration, token_count_duration, split_duration, embedding_duration, search_duration, upsert_duration, chunk_count, detail)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (task_id) DO UPDATE
+SET started_at           = EXCLUDED.started_at,
+    finished_at          = EXCLUDED.finished_at,
+    extract_duration     = EXCLUDED.extract_duration,
+    token_count_duration = EXCLUDED.token_count_duration,
+    split_duration       = EXCLUDED.split_duration,
+    embedding_duration   = EXCLUDED.embedding_duration,
+    search_duration      = EXCLUDED.search_duration,
+    upsert_duration      = EXCLUDED.upsert_duration,
+    chunk_count          = EXCLUDED.chunk_count,
+    detail               = EXCLUDED.detail;
+
+-- name: TaskStatGetByTaskId :one
+SELECT *
+FROM task_stats
+WHERE task_id = $1;
+
+-- name: TaskStatLatencyByType :many
+SELECT
+    tasks.type,
+    COUNT(*) as task_count,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY task_stats.extract_duration)::DOUBLE PRECISION as extract_p50,
+    percentile_cont(0.95) WITHIN GROUP (ORDER BY task_stats.extract_duration)::DOUBLE PRECISION as extract_p95,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY task_stats.token_count_duration)::DOUBLE PRECISION as token_count_p50,
+    percentile_cont(0.95) WITHIN GROUP (ORDER BY task_stats.token_count_duration)::DOUBLE PRECISION as token_count_p95,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY task_stats.split_duration)::DOUBLE PRECISION as split_p50,
+    percentile_cont(0.95) WITHIN GROUP (ORDER BY task_stats.split_duration)::DOUBLE PRECISION as split_p95,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY task_stats.embedding_duration)::DOUBLE PRECISION as embedding_p50,
+    percentile_cont(0.95) WITHIN GROUP (ORDER BY task_stats.embedding_duration)::DOUBLE PRECISION as embedding_p95,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY task_stats.search_duration)::DOUBLE PRECISION as search_p50,
+    percentile_cont(0.95) WITHIN GROUP (ORDER BY task_stats.search_duration)::DOUBLE PRECISION as search_p95,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY task_stats.upsert_duration)::DOUBLE PRECISION as upsert_p50,
+    percentile_cont(0.95) WITHIN GROUP (ORDER BY task_stats.upsert_duration)::DOUBLE PRECISION as upsert_p95,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM task_stats.finished_at - task_stats.started_at) * 1000)::DOUBLE PRECISION as total_p50,
+    percentile_cont(0.95) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM task_stats.finished_at - task_stats.started_at) * 1000)::DOUBLE PRECISION as total_p95
+FROM task_stats
+JOIN tasks ON tasks.id = task_stats.task_id
+WHERE task_stats.finished_at >= sqlc.arg('since')::TIMESTAMP
+GROUP BY tasks.type
+ORDER BY tasks.type;
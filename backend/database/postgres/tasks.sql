@@ -1,8 +1,15 @@
 -- name: TaskCreateForUserId :one
-INSERT INTO tasks (user_id, upload_id, category_id, type, source, is_raw, title, content)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO tasks (user_id, upload_id, category_id, type, source, is_raw, title, content, content_sha256, canonical_source, source_host, pii)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (canonical_source) WHERE status <> 'failed' DO NOTHING
 RETURNING *;
 
+-- name: TaskGetByCanonicalSource :one
+SELECT *
+FROM tasks
+WHERE canonical_source = $1
+  AND status <> 'failed';
+
 -- name: TaskListByUserId :many
 SELECT id, user_id, upload_id, category_id, type, source, status, failed_reason, token_count, created_at, updated_at
 FROM tasks
@@ -37,7 +44,8 @@ daily_stats AS (
         COUNT(tasks.id) FILTER (WHERE DATE(tasks.created_at) = d.day_date) as submitted,
         COUNT(tasks.id) FILTER (WHERE tasks.status IN ('queuing', 'processing') AND DATE(tasks.created_at) = d.day_date) as pending,
         COUNT(tasks.id) FILTER (WHERE tasks.status = 'completed' AND DATE(tasks.created_at) = d.day_date) as completed,
-        COUNT(tasks.id) FILTER (WHERE tasks.status = 'failed' AND DATE(tasks.created_at) = d.day_date) as failed
+        COUNT(tasks.id) FILTER (WHERE tasks.status = 'failed' AND DATE(tasks.created_at) = d.day_date) as failed,
+        COUNT(tasks.id) FILTER (WHERE tasks.status = 'low_quality' AND DATE(tasks.created_at) = d.day_date) as low_quality
     FROM (
         SELECT DATE(NOW() - INTERVAL '1 day' * generate_series(0, 6)) as day_date
     ) d
@@ -50,7 +58,8 @@ SELECT
     daily_stats.submitted,
     daily_stats.pending,
     daily_stats.completed,
-    daily_stats.failed
+    daily_stats.failed,
+    daily_stats.low_quality
 FROM daily_stats, token_stats;
 
 -- name: PoolTokenOverviewByCategory :many
@@ -63,51 +72,240 @@ LEFT JOIN tasks ON categories.id = tasks.category_id
 GROUP BY categories.id, categories.name
 ORDER BY categories.name;
 
--- name: TaskClaimPending :one
-UPDATE tasks
-SET status = 'processing'
-WHERE id = (
-    SELECT t.id
-    FROM tasks t
-    WHERE t.status = 'queuing'
-      AND t.user_id = (
-        SELECT user_id
-        FROM (SELECT DISTINCT user_id FROM tasks WHERE status = 'queuing') users
-        ORDER BY RANDOM()
-        LIMIT 1
-    )
-    ORDER BY t.created_at
-    LIMIT 1
-    FOR UPDATE SKIP LOCKED
+-- name: TaskClaimCandidate :one
+WITH host_loads AS (
+    SELECT source_host,
+           COUNT(*) FILTER (WHERE status = 'processing')                      as processing,
+           COUNT(*) FILTER (WHERE claimed_at > NOW() - INTERVAL '1 minute') as claimed
+    FROM tasks
+    WHERE source_host IS NOT NULL
+      AND (status = 'processing' OR claimed_at > NOW() - INTERVAL '1 minute')
+    GROUP BY source_host
 )
+SELECT t.id, t.source_host
+FROM tasks t
+LEFT JOIN host_loads ON host_loads.source_host = t.source_host
+WHERE t.status = 'queuing'
+  AND t.user_id = sqlc.arg('user_id')
+  AND t.priority = sqlc.arg('priority')
+  AND (
+    host_loads.source_host IS NULL
+    OR (
+        host_loads.processing < COALESCE((sqlc.arg('host_limits')::JSONB -> t.source_host ->> 'concurrency')::INTEGER, sqlc.arg('host_concurrency')::INTEGER)
+        AND host_loads.claimed < COALESCE((sqlc.arg('host_limits')::JSONB -> t.source_host ->> 'rate')::INTEGER, sqlc.arg('host_rate')::INTEGER)
+    )
+  )
+ORDER BY t.created_at
+LIMIT 1
+FOR UPDATE OF t SKIP LOCKED;
+
+-- name: TaskHostLock :exec
+SELECT pg_advisory_xact_lock(hashtext(sqlc.arg('source_host')::TEXT));
+
+-- name: TaskClaimById :one
+UPDATE tasks
+SET status           = 'processing',
+    claimed_by       = sqlc.arg('claimed_by'),
+    claimed_at       = NOW(),
+    lease_expires_at = NOW() + (sqlc.arg('lease_seconds')::INTEGER * INTERVAL '1 second'),
+    attempt          = attempt + 1
+WHERE id = sqlc.arg('id')
+  AND status = 'queuing'
+  AND (
+    source_host IS NULL
+    OR (
+        (SELECT COUNT(*) FROM tasks loads WHERE loads.source_host = tasks.source_host AND loads.status = 'processing')
+            < COALESCE((sqlc.arg('host_limits')::JSONB -> tasks.source_host ->> 'concurrency')::INTEGER, sqlc.arg('host_concurrency')::INTEGER)
+        AND (SELECT COUNT(*) FROM tasks loads WHERE loads.source_host = tasks.source_host AND loads.claimed_at > NOW() - INTERVAL '1 minute')
+            < COALESCE((sqlc.arg('host_limits')::JSONB -> tasks.source_host ->> 'rate')::INTEGER, sqlc.arg('host_rate')::INTEGER)
+    )
+  )
+RETURNING *;
+
+-- name: TaskLeaseExtend :one
+UPDATE tasks
+SET lease_expires_at = NOW() + (sqlc.arg('lease_seconds')::INTEGER * INTERVAL '1 second')
+WHERE id = $1
+  AND claimed_by = $2
+  AND status = 'processing'
+RETURNING id;
+
+-- name: TaskRequeue :execrows
+UPDATE tasks
+SET status           = 'queuing',
+    lease_expires_at = NULL,
+    attempt          = GREATEST(attempt - 1, 0)
+WHERE id = $1
+  AND claimed_by = $2
+  AND status = 'processing';
+
+-- name: TaskRequeueExpired :many
+UPDATE tasks
+SET status           = 'queuing',
+    lease_expires_at = NULL
+WHERE status = 'processing'
+  AND (lease_expires_at IS NULL OR lease_expires_at < NOW())
+  AND attempt < sqlc.arg('max_attempt')::INTEGER
 RETURNING *;
 
--- name: TaskUpdateCompleted :exec
+-- name: TaskFailExpired :many
+UPDATE tasks
+SET status           = 'failed',
+    failed_reason    = 'lease expired after ' || attempt || ' attempts',
+    lease_expires_at = NULL
+WHERE status = 'processing'
+  AND (lease_expires_at IS NULL OR lease_expires_at < NOW())
+  AND attempt >= sqlc.arg('max_attempt')::INTEGER
+RETURNING *;
+
+-- name: TaskUpdateContentSha256 :exec
+UPDATE tasks
+SET content_sha256 = $2
+WHERE id = $1;
+
+-- name: TaskUpdateResolvedSource :exec
+UPDATE tasks
+SET resolved_source = sqlc.narg('resolved_source'),
+    source_host     = COALESCE(sqlc.narg('source_host'), source_host)
+WHERE id = sqlc.arg('id');
+
+-- name: TaskUpdateExtractor :exec
+UPDATE tasks
+SET extractor = $2,
+    ocr_pages = $3
+WHERE id = $1;
+
+-- name: TaskGetExactDuplicate :one
+SELECT *
+FROM tasks
+WHERE content_sha256 = sqlc.arg('content_sha256')
+  AND type = sqlc.arg('type')
+  AND status = 'completed'
+  AND (sqlc.narg('id')::BIGINT IS NULL OR id <> sqlc.narg('id')::BIGINT)
+ORDER BY id
+LIMIT 1;
+
+-- name: TaskUpdateDedup :exec
+UPDATE tasks
+SET dedup = $2
+WHERE id = $1;
+
+-- name: TaskUpdateChunking :exec
 UPDATE tasks
-SET status          = 'completed',
-    title           = COALESCE($2, title),
-    content         = COALESCE($3, content),
-    token_count     = COALESCE($4, token_count),
-    revised_task_id = COALESCE($5, revised_task_id)
+SET chunking = $2
 WHERE id = $1;
 
--- name: TaskUpdateFailed :exec
+-- name: TaskUpdateCompleted :execrows
+UPDATE tasks
+SET status           = 'completed',
+    title            = COALESCE($2, title),
+    content          = COALESCE($3, content),
+    token_count      = COALESCE($4, token_count),
+    revised_task_id  = COALESCE($5, revised_task_id),
+    lease_expires_at = NULL
+WHERE id = $1
+  AND (sqlc.narg('claimed_by')::VARCHAR IS NULL OR (claimed_by = sqlc.narg('claimed_by') AND status = 'processing'));
+
+-- name: TaskUpdateFailed :execrows
 UPDATE tasks
 SET status = 'failed',
     failed_reason = $2,
     title = COALESCE($3, title),
     content = COALESCE($4, content),
-    token_count = COALESCE($5, token_count)
+    token_count = COALESCE($5, token_count),
+    lease_expires_at = NULL
+WHERE id = $1
+  AND (sqlc.narg('claimed_by')::VARCHAR IS NULL OR (claimed_by = sqlc.narg('claimed_by') AND status = 'processing'));
+
+-- name: TaskUpdatePii :exec
+UPDATE tasks
+SET pii = $2
 WHERE id = $1;
 
+-- name: TaskUpdateSuggestedCategory :exec
+UPDATE tasks
+SET suggested_category_id = $2,
+    suggested_confidence = $3
+WHERE id = $1;
+
+-- name: TaskUpdateCategory :exec
+UPDATE tasks
+SET submitted_category_id = COALESCE(submitted_category_id, category_id),
+    category_id = $2
+WHERE id = $1;
+
+-- name: TaskCountCategoryMismatch :one
+SELECT COUNT(*)
+FROM tasks
+WHERE tasks.suggested_category_id IS NOT NULL
+  AND (tasks.suggested_category_id IS DISTINCT FROM tasks.category_id OR tasks.submitted_category_id IS NOT NULL)
+  AND tasks.suggested_confidence >= sqlc.arg('min_confidence')::DOUBLE PRECISION;
+
+-- name: TaskListCategoryMismatch :many
+SELECT tasks.id,
+       tasks.title,
+       tasks.status,
+       tasks.category_id,
+       categories.name            as category_name,
+       tasks.suggested_category_id,
+       suggested_categories.name  as suggested_category_name,
+       tasks.suggested_confidence,
+       tasks.submitted_category_id,
+       submitted_categories.name  as submitted_category_name,
+       tasks.updated_at
+FROM tasks
+LEFT JOIN categories ON categories.id = tasks.category_id
+JOIN categories suggested_categories ON suggested_categories.id = tasks.suggested_category_id
+LEFT JOIN categories submitted_categories ON submitted_categories.id = tasks.submitted_category_id
+WHERE (tasks.suggested_category_id IS DISTINCT FROM tasks.category_id OR tasks.submitted_category_id IS NOT NULL)
+  AND tasks.suggested_confidence >= sqlc.arg('min_confidence')::DOUBLE PRECISION
+ORDER BY tasks.suggested_confidence DESC, tasks.id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: TaskUpdateMetadata :exec
+UPDATE tasks
+SET metadata = $2
+WHERE id = $1;
+
+-- name: TaskUpdateQuality :exec
+UPDATE tasks
+SET quality = $2
+WHERE id = $1;
+
+-- name: TaskUpdateLowQuality :execrows
+UPDATE tasks
+SET status = 'low_quality',
+    failed_reason = $2,
+    title = COALESCE($3, title),
+    content = COALESCE($4, content),
+    lease_expires_at = NULL
+WHERE id = $1
+  AND (sqlc.narg('claimed_by')::VARCHAR IS NULL OR (claimed_by = sqlc.narg('claimed_by') AND status = 'processing'));
+
 -- name: TaskResetFailed :many
 UPDATE tasks
 SET status = 'queuing',
     failed_reason = NULL,
     title = NULL,
     content = NULL,
-    token_count = 0
-WHERE status = 'failed'
+    content_sha256 = NULL,
+    resolved_source = NULL,
+    extractor = NULL,
+    ocr_pages = NULL,
+    quality = '{}',
+    pii = '{}',
+    metadata = '{}',
+    token_count = 0,
+    claimed_by = NULL,
+    attempt = 0
+WHERE status IN ('failed', 'low_quality')
+  AND NOT EXISTS (
+      SELECT 1
+      FROM tasks others
+      WHERE tasks.status = 'failed'
+        AND others.canonical_source = tasks.canonical_source
+        AND (others.status <> 'failed' OR others.id < tasks.id)
+  )
 RETURNING *;
 
 -- name: TaskListCompleted :many
@@ -116,6 +314,18 @@ FROM tasks
 WHERE status = 'completed'
 ORDER BY created_at;
 
+-- name: TaskUpdatePriorityByCategoryId :execrows
+UPDATE tasks
+SET priority = sqlc.arg('priority')
+WHERE status = 'queuing'
+  AND category_id = sqlc.arg('category_id')
+  AND (sqlc.narg('user_id')::BIGINT IS NULL OR user_id = sqlc.narg('user_id'));
+
+-- name: TaskCountByStatus :many
+SELECT status, COUNT(*) as count
+FROM tasks
+GROUP BY status;
+
 -- name: TaskTotalCompletedByUserId :one
 SELECT COUNT(*) as total_completed
 FROM tasks
@@ -131,9 +341,17 @@ SELECT COUNT(*) as total_pending
 FROM tasks
 WHERE user_id = $1 AND status IN ('queuing', 'processing');
 
+-- name: TaskTotalLowQualityByUserId :one
+SELECT COUNT(*) as total_low_quality
+FROM tasks
+WHERE user_id = $1 AND status = 'low_quality';
+
 -- name: TaskListFailedRawDuplicates :many
-SELECT *
+SELECT DISTINCT ON (tasks.id) sqlc.embed(tasks), task_duplicates.duplicate_of_task_id
 FROM tasks
-WHERE is_raw = true
-  AND status = 'failed'
-  AND failed_reason ~ 'duplicate #[0-9]+(:|\s)';
+JOIN task_duplicates ON task_duplicates.task_id = tasks.id
+JOIN tasks duplicate_tasks ON duplicate_tasks.id = task_duplicates.duplicate_of_task_id
+WHERE tasks.is_raw = true
+  AND tasks.status = 'failed'
+  AND duplicate_tasks.status = 'ignored'
+ORDER BY tasks.id, task_duplicates.score DESC;
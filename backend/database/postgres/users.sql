@@ -17,3 +17,8 @@ ORDER BY created_at DESC;
 INSERT INTO users (oid, firstname, lastname, email, photo_url)
 VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
+
+-- name: UserUpdateWeight :exec
+UPDATE users
+SET weight = $2
+WHERE id = $1;
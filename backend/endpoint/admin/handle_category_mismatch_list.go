@@ -0,0 +1,69 @@
+package adminEndpoint
+
+import (
+	"backend/generate/psql"
+	"backend/type/common"
+	"backend/type/payload"
+	"backend/type/response"
+	"github.com/bsthun/gut"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func (r *Handler) HandleCategoryMismatchList(c *fiber.Ctx) error {
+	// * get user claims
+	_ = c.Locals("l").(*jwt.Token).Claims.(*common.LoginClaims)
+
+	// * parse body
+	body := new(payload.AdminCategoryMismatchListRequest)
+	if err := c.BodyParser(body); err != nil {
+		return gut.Err(false, "invalid body", err)
+	}
+
+	// * validate body
+	if err := gut.Validate(body); err != nil {
+		return err
+	}
+	if body.MinConfidence == nil {
+		body.MinConfidence = gut.Ptr(0.0)
+	}
+
+	// * count tasks of suggested category differing from submitted category
+	count, err := r.database.P().TaskCountCategoryMismatch(c.Context(), body.MinConfidence)
+	if err != nil {
+		return gut.Err(false, "failed to count category mismatches", err)
+	}
+
+	// * list tasks
+	rows, err := r.database.P().TaskListCategoryMismatch(c.Context(), &psql.TaskListCategoryMismatchParams{
+		MinConfidence: body.MinConfidence,
+		Limit:         body.Paginate.Limit,
+		Offset:        body.Paginate.Offset,
+	})
+	if err != nil {
+		return gut.Err(false, "failed to list category mismatches", err)
+	}
+
+	// * map to response
+	items, _ := gut.Iterate(rows, func(row psql.TaskListCategoryMismatchRow) (*payload.AdminCategoryMismatchItem, *gut.ErrorInstance) {
+		return &payload.AdminCategoryMismatchItem{
+			TaskId:                row.Id,
+			Title:                 row.Title,
+			Status:                row.Status,
+			CategoryId:            row.CategoryId,
+			CategoryName:          row.CategoryName,
+			SuggestedCategoryId:   row.SuggestedCategoryId,
+			SuggestedCategoryName: row.SuggestedCategoryName,
+			SuggestedConfidence:   row.SuggestedConfidence,
+			SubmittedCategoryId:   row.SubmittedCategoryId,
+			SubmittedCategoryName: row.SubmittedCategoryName,
+			UpdatedAt:             row.UpdatedAt,
+		}, nil
+	})
+
+	// * response
+	return c.JSON(response.Success(c, &payload.AdminCategoryMismatchListResponse{
+		Count: count,
+		Tasks: items,
+	}))
+}
@@ -0,0 +1,61 @@
+package adminEndpoint
+
+import (
+	"backend/generate/psql"
+	"backend/type/common"
+	"backend/type/payload"
+	"backend/type/response"
+	"github.com/bsthun/gut"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func (r *Handler) HandleExtractEndpointList(c *fiber.Ctx) error {
+	// * get user claims
+	_ = c.Locals("l").(*jwt.Token).Claims.(*common.LoginClaims)
+
+	// * parse body
+	body := new(payload.AdminExtractEndpointListRequest)
+	if err := c.BodyParser(body); err != nil {
+		return gut.Err(false, "invalid body", err)
+	}
+
+	// * validate body
+	if err := gut.Validate(body); err != nil {
+		return err
+	}
+
+	// * list endpoint states reported by workers since given time
+	states, err := r.database.P().ExtractEndpointStateList(c.Context(), body.Since)
+	if err != nil {
+		return gut.Err(false, "failed to list extract endpoint states", err)
+	}
+
+	// * map to response
+	items, _ := gut.Iterate(states, func(state psql.ExtractEndpointState) (*payload.AdminExtractEndpointItem, *gut.ErrorInstance) {
+		var successRate *float64
+		if total := *state.SuccessCount + *state.FailureCount; total > 0 {
+			successRate = gut.Ptr(float64(*state.SuccessCount) / float64(total))
+		}
+		return &payload.AdminExtractEndpointItem{
+			WorkerId:            state.WorkerId,
+			Base:                state.Base,
+			State:               state.State,
+			Capacity:            state.Capacity,
+			Inflight:            state.Inflight,
+			ConsecutiveFailures: state.ConsecutiveFailures,
+			SuccessCount:        state.SuccessCount,
+			FailureCount:        state.FailureCount,
+			SuccessRate:         successRate,
+			Latency:             state.Latency,
+			OpenedAt:            state.OpenedAt,
+			LastError:           state.LastError,
+			UpdatedAt:           state.UpdatedAt,
+		}, nil
+	})
+
+	// * response
+	return c.JSON(response.Success(c, &payload.AdminExtractEndpointListResponse{
+		Endpoints: items,
+	}))
+}
@@ -0,0 +1,41 @@
+package adminEndpoint
+
+import (
+	"backend/generate/psql"
+	"backend/type/common"
+	"backend/type/payload"
+	"backend/type/response"
+	"github.com/bsthun/gut"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func (r *Handler) HandleQueueList(c *fiber.Ctx) error {
+	// * get user claims
+	_ = c.Locals("l").(*jwt.Token).Claims.(*common.LoginClaims)
+
+	// * list active flows of fair queue
+	rows, err := r.database.P().TaskQueueList(c.Context())
+	if err != nil {
+		return gut.Err(false, "failed to list queues", err)
+	}
+
+	// * map to response
+	items, _ := gut.Iterate(rows, func(row psql.TaskQueueListRow) (*payload.AdminQueueItem, *gut.ErrorInstance) {
+		return &payload.AdminQueueItem{
+			UserId:    row.UserId,
+			Firstname: row.Firstname,
+			Lastname:  row.Lastname,
+			Weight:    row.Weight,
+			Priority:  row.Priority,
+			Queued:    row.Queued,
+			Deficit:   row.Deficit,
+			ServedAt:  row.ServedAt,
+		}, nil
+	})
+
+	// * response
+	return c.JSON(response.Success(c, &payload.AdminQueueListResponse{
+		Queues: items,
+	}))
+}
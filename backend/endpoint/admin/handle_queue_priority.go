@@ -0,0 +1,42 @@
+package adminEndpoint
+
+import (
+	"backend/generate/psql"
+	"backend/type/common"
+	"backend/type/payload"
+	"backend/type/response"
+	"github.com/bsthun/gut"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func (r *Handler) HandleQueuePriority(c *fiber.Ctx) error {
+	// * get user claims
+	_ = c.Locals("l").(*jwt.Token).Claims.(*common.LoginClaims)
+
+	// * parse body
+	body := new(payload.AdminQueuePriorityRequest)
+	if err := c.BodyParser(body); err != nil {
+		return gut.Err(false, "invalid body", err)
+	}
+
+	// * validate body
+	if err := gut.Validate(body); err != nil {
+		return err
+	}
+
+	// * expedite queued tasks of category, optionally of single user
+	count, err := r.database.P().TaskUpdatePriorityByCategoryId(c.Context(), &psql.TaskUpdatePriorityByCategoryIdParams{
+		Priority:   body.Priority,
+		CategoryId: body.CategoryId,
+		UserId:     body.UserId,
+	})
+	if err != nil {
+		return gut.Err(false, "failed to update task priority", err)
+	}
+
+	// * response
+	return c.JSON(response.Success(c, &payload.AdminQueuePriorityResponse{
+		Count: &count,
+	}))
+}
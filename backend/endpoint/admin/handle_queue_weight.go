@@ -0,0 +1,50 @@
+package adminEndpoint
+
+import (
+	"backend/generate/psql"
+	"backend/type/common"
+	"backend/type/payload"
+	"backend/type/response"
+	"github.com/bsthun/gut"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func (r *Handler) HandleQueueWeight(c *fiber.Ctx) error {
+	// * get user claims
+	_ = c.Locals("l").(*jwt.Token).Claims.(*common.LoginClaims)
+
+	// * parse body
+	body := new(payload.AdminQueueWeightRequest)
+	if err := c.BodyParser(body); err != nil {
+		return gut.Err(false, "invalid body", err)
+	}
+
+	// * validate body
+	if err := gut.Validate(body); err != nil {
+		return err
+	}
+
+	// * update weight of category, which scales cost of its tasks
+	if body.CategoryId != nil {
+		if err := r.database.P().CategoryUpdateWeight(c.Context(), &psql.CategoryUpdateWeightParams{
+			Id:     body.CategoryId,
+			Weight: body.Weight,
+		}); err != nil {
+			return gut.Err(false, "failed to update category weight", err)
+		}
+	}
+
+	// * update weight of user, which is quantum of its flows each round
+	if body.UserId != nil {
+		if err := r.database.P().UserUpdateWeight(c.Context(), &psql.UserUpdateWeightParams{
+			Id:     body.UserId,
+			Weight: body.Weight,
+		}); err != nil {
+			return gut.Err(false, "failed to update user weight", err)
+		}
+	}
+
+	// * response
+	return c.JSON(response.Success(c, nil))
+}
@@ -0,0 +1,74 @@
+package adminEndpoint
+
+import (
+	"backend/generate/psql"
+	"backend/type/common"
+	"backend/type/payload"
+	"backend/type/response"
+	"github.com/bsthun/gut"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func (r *Handler) HandleStatLatency(c *fiber.Ctx) error {
+	// * get user claims
+	_ = c.Locals("l").(*jwt.Token).Claims.(*common.LoginClaims)
+
+	// * parse body
+	body := new(payload.AdminStatLatencyRequest)
+	if err := c.BodyParser(body); err != nil {
+		return gut.Err(false, "invalid body", err)
+	}
+
+	// * validate body
+	if err := gut.Validate(body); err != nil {
+		return err
+	}
+
+	// * aggregate stage latencies by task type
+	rows, err := r.database.P().TaskStatLatencyByType(c.Context(), body.Since)
+	if err != nil {
+		return gut.Err(false, "failed to aggregate stage latency", err)
+	}
+
+	// * map to response
+	items, _ := gut.Iterate(rows, func(row psql.TaskStatLatencyByTypeRow) (*payload.AdminStatLatencyItem, *gut.ErrorInstance) {
+		return &payload.AdminStatLatencyItem{
+			Type:      row.Type,
+			TaskCount: row.TaskCount,
+			Extract: &payload.AdminStatLatencyStage{
+				P50: row.ExtractP50,
+				P95: row.ExtractP95,
+			},
+			TokenCount: &payload.AdminStatLatencyStage{
+				P50: row.TokenCountP50,
+				P95: row.TokenCountP95,
+			},
+			Split: &payload.AdminStatLatencyStage{
+				P50: row.SplitP50,
+				P95: row.SplitP95,
+			},
+			Embedding: &payload.AdminStatLatencyStage{
+				P50: row.EmbeddingP50,
+				P95: row.EmbeddingP95,
+			},
+			Search: &payload.AdminStatLatencyStage{
+				P50: row.SearchP50,
+				P95: row.SearchP95,
+			},
+			Upsert: &payload.AdminStatLatencyStage{
+				P50: row.UpsertP50,
+				P95: row.UpsertP95,
+			},
+			Total: &payload.AdminStatLatencyStage{
+				P50: row.TotalP50,
+				P95: row.TotalP95,
+			},
+		}, nil
+	})
+
+	// * response
+	return c.JSON(response.Success(c, &payload.AdminStatLatencyResponse{
+		Types: items,
+	}))
+}
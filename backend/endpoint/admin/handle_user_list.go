@@ -30,6 +30,7 @@ func (r *Handler) HandleUserList(c *fiber.Ctx) error {
 			Email:     user.Email,
 			PhotoUrl:  user.PhotoUrl,
 			IsAdmin:   user.IsAdmin,
+			Weight:    user.Weight,
 			CreatedAt: user.CreatedAt,
 			UpdatedAt: user.UpdatedAt,
 		}, nil
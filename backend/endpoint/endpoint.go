@@ -20,6 +20,9 @@ func Bind(
 	middleware *middleware.Middleware,
 	config *config.Config,
 ) {
+	// * observe latency of every route
+	app.Use(middleware.Metric())
+
 	api := app.Group("/api")
 	api.Use(middleware.Id())
 
@@ -39,12 +42,19 @@ func Bind(
 	task.Post("/submit/batch", taskEndpoint.HandleTaskSubmitBatch)
 	task.Post("/list", taskEndpoint.HandleTaskList)
 	task.Post("/detail", taskEndpoint.HandleTaskDetail)
+	task.Post("/chunk/list", taskEndpoint.HandleTaskChunkList)
 	task.Post("/category/list", taskEndpoint.HandleTaskCategoryList)
 	task.Post("/upload/list", taskEndpoint.HandleTaskUploadList)
 
 	// * admin endpoints
-	admin := api.Group("/admin", middleware.Jwt(true))
+	admin := api.Group("/admin", middleware.Jwt(true), middleware.Admin())
 	admin.Post("/user/list", adminEndpoint.HandleUserList)
+	admin.Post("/stat/latency", adminEndpoint.HandleStatLatency)
+	admin.Post("/extract/endpoint/list", adminEndpoint.HandleExtractEndpointList)
+	admin.Post("/category/mismatch/list", adminEndpoint.HandleCategoryMismatchList)
+	admin.Post("/queue/list", adminEndpoint.HandleQueueList)
+	admin.Post("/queue/priority", adminEndpoint.HandleQueuePriority)
+	admin.Post("/queue/weight", adminEndpoint.HandleQueueWeight)
 
 	// * static files
 	app.Static("/file", ".local/file")
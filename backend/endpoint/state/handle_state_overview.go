@@ -50,6 +50,12 @@ func (r *Handler) HandleStateOverview(c *fiber.Ctx) error {
 		return gut.Err(false, "failed to get total pending count", err)
 	}
 
+	// * get total low quality count
+	totalLowQuality, err := r.database.P().TaskTotalLowQualityByUserId(c.Context(), u.UserId)
+	if err != nil {
+		return gut.Err(false, "failed to get total low quality count", err)
+	}
+
 	// * get pool token
 	categoryRows, err := r.database.P().PoolTokenOverviewByCategory(c.Context())
 	if err != nil {
@@ -59,10 +65,11 @@ func (r *Handler) HandleStateOverview(c *fiber.Ctx) error {
 	// * map daily stats to histories array
 	histories, _ := gut.Iterate(statsRows, func(row psql.TaskOverviewByUserIdRow) (*payload.OverviewHistoryItem, *gut.ErrorInstance) {
 		return &payload.OverviewHistoryItem{
-			Submitted: row.Submitted,
-			Pending:   row.Pending,
-			Completed: row.Completed,
-			Failed:    row.Failed,
+			Submitted:  row.Submitted,
+			Pending:    row.Pending,
+			Completed:  row.Completed,
+			Failed:     row.Failed,
+			LowQuality: row.LowQuality,
 		}, nil
 	})
 
@@ -77,11 +84,12 @@ func (r *Handler) HandleStateOverview(c *fiber.Ctx) error {
 
 	// * response
 	return c.JSON(response.Success(c, &payload.Overview{
-		TokenCount:     statsRows[0].TokenCount,
-		TotalCompleted: totalCompleted,
-		TotalFailed:    totalFailed,
-		TotalPending:   totalPending,
-		Histories:      histories,
-		PoolTokens:     poolTokens,
+		TokenCount:      statsRows[0].TokenCount,
+		TotalCompleted:  totalCompleted,
+		TotalFailed:     totalFailed,
+		TotalPending:    totalPending,
+		TotalLowQuality: totalLowQuality,
+		Histories:       histories,
+		PoolTokens:      poolTokens,
 	}))
 }
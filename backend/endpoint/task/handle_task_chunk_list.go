@@ -0,0 +1,66 @@
+package taskEndpoint
+
+import (
+	"backend/generate/psql"
+	"backend/type/common"
+	"backend/type/payload"
+	"backend/type/response"
+
+	"github.com/bsthun/gut"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func (r *Handler) HandleTaskChunkList(c *fiber.Ctx) error {
+	// * login claims
+	l := c.Locals("l").(*jwt.Token).Claims.(*common.LoginClaims)
+	_ = l
+
+	// * parse body
+	body := new(payload.TaskChunkListRequest)
+	if err := c.BodyParser(body); err != nil {
+		return gut.Err(false, "invalid body", err)
+	}
+
+	// * validate body
+	if err := gut.Validate(body); err != nil {
+		return err
+	}
+
+	// * list chunks with duplicate matches
+	chunks, err := r.database.P().TaskChunkListByTaskId(c.Context(), body.TaskId)
+	if err != nil {
+		return gut.Err(false, "failed to list task chunks", err)
+	}
+
+	// * map to response
+	chunkItems, _ := gut.Iterate(chunks, func(chunk psql.TaskChunkListByTaskIdRow) (*payload.TaskChunkItem, *gut.ErrorInstance) {
+		var duplicate *payload.TaskChunkDuplicate
+		if chunk.DuplicateTaskId != nil {
+			duplicate = &payload.TaskChunkDuplicate{
+				TaskId:      chunk.DuplicateTaskId,
+				Title:       chunk.DuplicateTitle,
+				Status:      chunk.DuplicateStatus,
+				ChunkNo:     chunk.DuplicateChunkNo,
+				StartOffset: chunk.DuplicateStartOffset,
+				EndOffset:   chunk.DuplicateEndOffset,
+				Score:       chunk.DuplicateScore,
+			}
+		}
+		return &payload.TaskChunkItem{
+			Id:          chunk.Id,
+			ChunkNo:     chunk.ChunkNo,
+			StartOffset: chunk.StartOffset,
+			EndOffset:   chunk.EndOffset,
+			TokenCount:  chunk.TokenCount,
+			Hash:        chunk.Hash,
+			PointId:     chunk.PointId,
+			Duplicate:   duplicate,
+		}, nil
+	})
+
+	// * response
+	return c.JSON(response.Success(c, &payload.TaskChunkListResponse{
+		Chunks: chunkItems,
+	}))
+}
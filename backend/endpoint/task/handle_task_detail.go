@@ -1,11 +1,12 @@
 package taskEndpoint
 
 import (
+	"backend/generate/psql"
 	"backend/type/common"
 	"backend/type/payload"
 	"backend/type/response"
-	"regexp"
-	"strconv"
+	"database/sql"
+	"errors"
 
 	"github.com/bsthun/gut"
 	"github.com/gofiber/fiber/v2"
@@ -15,7 +16,6 @@ import (
 func (r *Handler) HandleTaskDetail(c *fiber.Ctx) error {
 	// * login claims
 	l := c.Locals("l").(*jwt.Token).Claims.(*common.LoginClaims)
-	_ = l
 
 	// * parse body
 	body := new(payload.TaskDetailRequest)
@@ -29,37 +29,77 @@ func (r *Handler) HandleTaskDetail(c *fiber.Ctx) error {
 		return gut.Err(false, "task not found or not owned by user", err)
 	}
 
-	// * replace encode ids
-	if task.Task.FailedReason != nil {
-		re := regexp.MustCompile(`(\s)#(\d+)(\s)`)
-		*task.Task.FailedReason = re.ReplaceAllStringFunc(*task.Task.FailedReason, func(match string) string {
-			submatch := re.FindStringSubmatch(match)
-			if len(submatch) == 4 {
-				num, err := strconv.ParseUint(submatch[2], 10, 64)
-				if err == nil {
-					return submatch[1] + "#" + gut.EncodeId(num) + submatch[3]
-				}
-			}
-			return match
-		})
+	// * get task stat
+	var stat *payload.TaskStatItem
+	taskStat, err := r.database.P().TaskStatGetByTaskId(c.Context(), task.Task.Id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return gut.Err(false, "failed to get task stat", err)
 	}
+	if err == nil {
+		stat = &payload.TaskStatItem{
+			StartedAt:          taskStat.StartedAt,
+			FinishedAt:         taskStat.FinishedAt,
+			ExtractDuration:    taskStat.ExtractDuration,
+			TokenCountDuration: taskStat.TokenCountDuration,
+			SplitDuration:      taskStat.SplitDuration,
+			EmbeddingDuration:  taskStat.EmbeddingDuration,
+			SearchDuration:     taskStat.SearchDuration,
+			UpsertDuration:     taskStat.UpsertDuration,
+			ChunkCount:         taskStat.ChunkCount,
+			Detail:             taskStat.Detail,
+		}
+	}
+
+	// * list duplicate relationships
+	taskDuplicates, err := r.database.P().TaskDuplicateListByTaskId(c.Context(), task.Task.Id)
+	if err != nil {
+		return gut.Err(false, "failed to list task duplicates", err)
+	}
+	duplicates, _ := gut.Iterate(taskDuplicates, func(duplicate psql.TaskDuplicateListByTaskIdRow) (*payload.TaskDuplicateItem, *gut.ErrorInstance) {
+		item := &payload.TaskDuplicateItem{
+			TaskId:  gut.Ptr(gut.EncodeId(*duplicate.DuplicateOfTaskId)),
+			Title:   nil,
+			Status:  nil,
+			ChunkNo: duplicate.ChunkNo,
+			Score:   duplicate.Score,
+		}
+
+		// * title and status of duplicate are only shown to its owner
+		if duplicate.DuplicateUserId != nil && *duplicate.DuplicateUserId == *l.UserId {
+			item.Title = duplicate.DuplicateTitle
+			item.Status = duplicate.DuplicateStatus
+		}
+
+		return item, nil
+	})
 
 	// * response
 	return c.JSON(response.Success(c, &payload.TaskDetailResponse{
-		Id:           task.Task.Id,
-		UserId:       task.Task.UserId,
-		UploadId:     task.Task.UploadId,
-		CategoryId:   task.Task.CategoryId,
-		Type:         task.Task.Type,
-		Source:       task.Task.Source,
-		IsRaw:        task.Task.IsRaw,
-		Status:       task.Task.Status,
-		FailedReason: task.Task.FailedReason,
-		Title:        task.Task.Title,
-		Content:      task.Task.Content,
-		TokenCount:   task.Task.TokenCount,
-		CreatedAt:    task.Task.CreatedAt,
-		UpdatedAt:    task.Task.UpdatedAt,
+		Id:                  task.Task.Id,
+		UserId:              task.Task.UserId,
+		UploadId:            task.Task.UploadId,
+		CategoryId:          task.Task.CategoryId,
+		Type:                task.Task.Type,
+		Source:              task.Task.Source,
+		ResolvedSource:      task.Task.ResolvedSource,
+		IsRaw:               task.Task.IsRaw,
+		Status:              task.Task.Status,
+		FailedReason:        task.Task.FailedReason,
+		Title:               task.Task.Title,
+		Content:             task.Task.Content,
+		TokenCount:          task.Task.TokenCount,
+		Dedup:               task.Task.Dedup,
+		Extractor:           task.Task.Extractor,
+		OcrPages:            task.Task.OcrPages,
+		Quality:             task.Task.Quality,
+		Pii:                 task.Task.Pii,
+		Metadata:            task.Task.Metadata,
+		Priority:            task.Task.Priority,
+		SuggestedCategoryId: task.Task.SuggestedCategoryId,
+		SuggestedConfidence: task.Task.SuggestedConfidence,
+		SubmittedCategoryId: task.Task.SubmittedCategoryId,
+		CreatedAt:           task.Task.CreatedAt,
+		UpdatedAt:           task.Task.UpdatedAt,
 		User: &payload.UserListItem{
 			Id:        task.User.Id,
 			Oid:       task.User.Oid,
@@ -77,5 +117,7 @@ func (r *Handler) HandleTaskDetail(c *fiber.Ctx) error {
 			CreatedAt: task.Category.CreatedAt,
 			UpdatedAt: task.Category.UpdatedAt,
 		},
+		Stat:       stat,
+		Duplicates: duplicates,
 	}))
 }
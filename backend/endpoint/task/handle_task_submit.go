@@ -25,13 +25,15 @@ func (r *Handler) HandleTaskSubmit(c *fiber.Ctx) error {
 	}
 
 	// * create task
-	task, er := r.taskProcedure.TaskCreate(c.Context(), r.database.P(), l.UserId, nil, body.Category, body.Type, body.Source)
+	task, existing, er := r.taskProcedure.TaskCreate(c.Context(), r.database.P(), l.UserId, nil, body.Category, body.Type, body.Source)
 	if er != nil {
 		return er
 	}
 
 	// * response
 	return c.JSON(response.Success(c, &payload.TaskSubmitResponse{
-		TaskId: task.Id,
+		TaskId:   task.Id,
+		Existing: &existing,
+		Status:   task.Status,
 	}))
 }
@@ -56,6 +56,7 @@ func (r *Handler) HandleTaskSubmitBatch(c *fiber.Ctx) error {
 	}
 
 	var createdTasks []*psql.Task
+	var existingTasks []*payload.TaskSubmitBatchExistingItem
 
 	// * iterate through csv records
 	for i, record := range records {
@@ -74,6 +75,12 @@ func (r *Handler) HandleTaskSubmitBatch(c *fiber.Ctx) error {
 		taskType := strings.TrimSpace(record[2])
 		content := strings.TrimSpace(record[3])
 
+		// * optional title column of raw content
+		var title *string
+		if len(record) > 4 && strings.TrimSpace(record[4]) != "" {
+			title = gut.Ptr(strings.TrimSpace(record[4]))
+		}
+
 		if taskType == "pdf" {
 			taskType = "doc"
 		}
@@ -84,19 +91,29 @@ func (r *Handler) HandleTaskSubmitBatch(c *fiber.Ctx) error {
 		}
 
 		var task *psql.Task
+		var existing bool
 		var er *gut.ErrorInstance
 
 		// * check content
 		if content != "" {
-			task, er = r.taskProcedure.TaskRawCreate(c.Context(), querier, l.UserId, upload.Id, &category, &taskType, &source, gut.Ptr(""), &content)
+			task, er = r.taskProcedure.TaskRawCreate(c.Context(), querier, l.UserId, upload.Id, &category, &taskType, &source, title, &content)
 		} else {
-			task, er = r.taskProcedure.TaskCreate(c.Context(), querier, l.UserId, upload.Id, &category, &taskType, &source)
+			task, existing, er = r.taskProcedure.TaskCreate(c.Context(), querier, l.UserId, upload.Id, &category, &taskType, &source)
 		}
 
 		if er != nil {
 			return er
 		}
 
+		// * report only id and status of existing task, which may belong to another user
+		if existing {
+			existingTasks = append(existingTasks, &payload.TaskSubmitBatchExistingItem{
+				TaskId: task.Id,
+				Status: task.Status,
+			})
+			continue
+		}
+
 		createdTasks = append(createdTasks, task)
 	}
 
@@ -107,7 +124,9 @@ func (r *Handler) HandleTaskSubmitBatch(c *fiber.Ctx) error {
 
 	// * response
 	return c.JSON(response.Success(c, &payload.TaskSubmitBatchResponse{
-		TasksCreated: gut.Ptr(len(createdTasks)),
-		Tasks:        createdTasks,
+		TasksCreated:  gut.Ptr(len(createdTasks)),
+		TasksExisting: gut.Ptr(len(existingTasks)),
+		Tasks:         createdTasks,
+		ExistingTasks: existingTasks,
 	}))
 }
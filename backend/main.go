@@ -3,8 +3,10 @@ package main
 import (
 	"backend/common/config"
 	"backend/common/database"
+	"backend/common/extractor"
 	"backend/common/fiber"
 	"backend/common/fiber/middleware"
+	"backend/common/metric"
 	"backend/common/ollama"
 	"backend/common/qdrant"
 	"backend/endpoint"
@@ -30,6 +32,8 @@ func main() {
 			database.Init,
 			qdrant.Init,
 			ollama.Init,
+			extractor.Init,
+			metric.Init,
 			fiber.Init,
 			middleware.Init,
 			taskProcedure.Serve,
@@ -40,6 +44,7 @@ func main() {
 		),
 		fx.Invoke(
 			endpoint.Bind,
+			metric.Serve,
 		),
 	).Run()
 }
@@ -0,0 +1,50 @@
+package chunkProcedure
+
+import (
+	"backend/generate/psql"
+	"backend/util/chunker"
+	"context"
+	"strconv"
+
+	"github.com/bsthun/gut"
+	qd "github.com/qdrant/go-client/qdrant"
+)
+
+func (r *Service) ChunkSave(ctx context.Context, querier psql.PQuerier, taskId *uint64, chunk *chunker.Chunk, pointId *string, match *qd.ScoredPoint) *gut.ErrorInstance {
+	params := &psql.TaskChunkUpsertParams{
+		TaskId:           taskId,
+		ChunkNo:          gut.Ptr(int32(chunk.No)),
+		StartOffset:      nil,
+		EndOffset:        nil,
+		TokenCount:       nil,
+		Hash:             &chunk.Hash,
+		PointId:          pointId,
+		DuplicateTaskId:  nil,
+		DuplicateChunkNo: nil,
+		DuplicateScore:   nil,
+	}
+	if chunk.Start != nil {
+		params.StartOffset = gut.Ptr(int32(*chunk.Start))
+		params.EndOffset = gut.Ptr(int32(*chunk.End))
+	}
+	if chunk.TokenCount != nil {
+		params.TokenCount = gut.Ptr(int32(*chunk.TokenCount))
+	}
+
+	// * attach best duplicate match
+	if match != nil {
+		duplicateTaskId, err := strconv.ParseUint(match.Payload["taskId"].GetStringValue(), 10, 64)
+		if err != nil {
+			return gut.Err(false, "invalid duplicate task id in point payload", err)
+		}
+		params.DuplicateTaskId = &duplicateTaskId
+		params.DuplicateChunkNo = gut.Ptr(int32(match.Payload["chunkNo"].GetIntegerValue()))
+		params.DuplicateScore = gut.Ptr(float64(match.Score))
+	}
+
+	if err := querier.TaskChunkUpsert(ctx, params); err != nil {
+		return gut.Err(false, "failed to upsert task chunk", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package chunkProcedure
+
+import (
+	"backend/generate/psql"
+	"backend/util/chunker"
+	"context"
+	"github.com/bsthun/gut"
+	qd "github.com/qdrant/go-client/qdrant"
+)
+
+type Server interface {
+	ChunkSave(ctx context.Context, querier psql.PQuerier, taskId *uint64, chunk *chunker.Chunk, pointId *string, match *qd.ScoredPoint) *gut.ErrorInstance
+}
+
+type Service struct {
+}
+
+func Serve() Server {
+	return &Service{}
+}
@@ -0,0 +1,36 @@
+package dedupProcedure
+
+import (
+	"backend/generate/psql"
+	"backend/util/dedup"
+	"context"
+	"encoding/json"
+
+	"github.com/bsthun/gut"
+)
+
+func (r *Service) DedupResolve(ctx context.Context, querier psql.PQuerier, task *psql.Task) (*dedup.Policy, *gut.ErrorInstance) {
+	// * apply override of task category
+	policy := r.config.Dedup
+	if len(r.config.DedupCategories) > 0 {
+		category, err := querier.CategoryGetById(ctx, task.CategoryId)
+		if err != nil {
+			return nil, gut.Err(false, "failed to get task category", err)
+		}
+		policy = policy.Merge(r.config.DedupCategories[*category.Name])
+	}
+
+	// * record policy for auditability
+	policyJson, err := json.Marshal(policy)
+	if err != nil {
+		return nil, gut.Err(false, "failed to marshal dedup policy", err)
+	}
+	if err := querier.TaskUpdateDedup(ctx, &psql.TaskUpdateDedupParams{
+		Id:    task.Id,
+		Dedup: policyJson,
+	}); err != nil {
+		return nil, gut.Err(false, "failed to record dedup policy", err)
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,23 @@
+package dedupProcedure
+
+import (
+	"backend/common/config"
+	"backend/generate/psql"
+	"backend/util/dedup"
+	"context"
+	"github.com/bsthun/gut"
+)
+
+type Server interface {
+	DedupResolve(ctx context.Context, querier psql.PQuerier, task *psql.Task) (*dedup.Policy, *gut.ErrorInstance)
+}
+
+type Service struct {
+	config *config.Config
+}
+
+func Serve(config *config.Config) Server {
+	return &Service{
+		config: config,
+	}
+}
@@ -2,32 +2,76 @@ package taskProcedure
 
 import (
 	"backend/generate/psql"
+	"backend/util/canonical"
+	"backend/util/resolver"
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 
 	"github.com/bsthun/gut"
 )
 
-func (r *Service) TaskCreate(ctx context.Context, querier psql.PQuerier, userId *uint64, uploadId *uint64, categoryName *string, taskType *string, source *string) (*psql.Task, *gut.ErrorInstance) {
+func (r *Service) TaskCreate(ctx context.Context, querier psql.PQuerier, userId *uint64, uploadId *uint64, categoryName *string, taskType *string, source *string) (*psql.Task, bool, *gut.ErrorInstance) {
+	// * validate task type against extractor registry
+	if _, ok := r.extractor.Get(*taskType); !ok {
+		return nil, false, gut.Err(false, fmt.Sprintf("unsupported task type %s", *taskType), nil)
+	}
+
+	// * canonicalize source url
+	canonicalSource, err := canonical.Url(*source)
+	if err != nil {
+		return nil, false, gut.Err(false, "invalid source url", err)
+	}
+
+	// * host limits apply to host extraction connects to after share link resolution
+	sourceHost, ok := resolver.Host(*source)
+	if !ok {
+		return nil, false, gut.Err(false, "invalid source url", nil)
+	}
+
+	// * report existing task of same canonical source
+	existingTask, err := querier.TaskGetByCanonicalSource(ctx, &canonicalSource)
+	if err == nil {
+		return &existingTask, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, gut.Err(false, "failed to get task by canonical source", err)
+	}
+
 	// * get category by name
 	category, err := querier.CategoryGetByName(ctx, categoryName)
 	if err != nil {
-		return nil, gut.Err(false, "category not found", err)
+		return nil, false, gut.Err(false, "category not found", err)
 	}
 
 	// * create task
 	task, err := querier.TaskCreateForUserId(ctx, &psql.TaskCreateForUserIdParams{
-		UserId:     userId,
-		UploadId:   uploadId,
-		CategoryId: category.Id,
-		Type:       taskType,
-		Source:     source,
-		IsRaw:      gut.Ptr(false),
-		Title:      nil,
-		Content:    nil,
+		UserId:          userId,
+		UploadId:        uploadId,
+		CategoryId:      category.Id,
+		Type:            taskType,
+		Source:          source,
+		IsRaw:           gut.Ptr(false),
+		Title:           nil,
+		Content:         nil,
+		ContentSha256:   nil,
+		CanonicalSource: &canonicalSource,
+		SourceHost:      &sourceHost,
+		Pii:             json.RawMessage("{}"),
 	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// * canonical source was taken by concurrent submission
+		existingTask, err := querier.TaskGetByCanonicalSource(ctx, &canonicalSource)
+		if err != nil {
+			return nil, false, gut.Err(false, "failed to get task by canonical source", err)
+		}
+		return &existingTask, true, nil
+	}
 	if err != nil {
-		return nil, gut.Err(false, "failed to create task", err)
+		return nil, false, gut.Err(false, "failed to create task", err)
 	}
 
-	return &task, nil
+	return &task, false, nil
 }
@@ -2,31 +2,98 @@ package taskProcedure
 
 import (
 	"backend/generate/psql"
+	"backend/util/fingerprint"
+	"backend/util/pii"
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/bsthun/gut"
 )
 
 func (r *Service) TaskRawCreate(ctx context.Context, querier psql.PQuerier, userId *uint64, uploadId *uint64, categoryName *string, taskType *string, source *string, title *string, content *string) (*psql.Task, *gut.ErrorInstance) {
+	// * validate task type against extractor registry
+	if _, ok := r.extractor.Get(*taskType); !ok {
+		return nil, gut.Err(false, fmt.Sprintf("unsupported task type %s", *taskType), nil)
+	}
+
 	// * get category by name
 	category, err := querier.CategoryGetByName(ctx, categoryName)
 	if err != nil {
 		return nil, gut.Err(false, "category not found", err)
 	}
 
+	// * redact personal information of category policy before content is stored
+	policy := r.config.Pii.Merge(r.config.PiiCategories[*category.Name])
+	scrubbedContent, counts := pii.Scrub(*content, policy)
+	if title != nil {
+		scrubbedTitle, titleCounts := pii.Scrub(*title, policy)
+		for piiType, count := range titleCounts {
+			counts[piiType] += count
+		}
+		title = &scrubbedTitle
+	}
+	piiCounts, err := json.Marshal(counts)
+	if err != nil {
+		return nil, gut.Err(false, "failed to encode redaction counts", err)
+	}
+
+	// * find completed exact duplicate of normalized content
+	contentSha256 := fingerprint.ContentSha256(scrubbedContent)
+	duplicateTask, err := querier.TaskGetExactDuplicate(ctx, &psql.TaskGetExactDuplicateParams{
+		ContentSha256: &contentSha256,
+		Type:          taskType,
+		Id:            nil,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, gut.Err(false, "failed to check exact duplicate", err)
+	}
+
 	// * create raw task with title and content
 	task, err := querier.TaskCreateForUserId(ctx, &psql.TaskCreateForUserIdParams{
-		UserId:     userId,
-		UploadId:   uploadId,
-		CategoryId: category.Id,
-		Type:       taskType,
-		Source:     source,
-		IsRaw:      gut.Ptr(true),
-		Title:      title,
-		Content:    content,
+		UserId:          userId,
+		UploadId:        uploadId,
+		CategoryId:      category.Id,
+		Type:            taskType,
+		Source:          source,
+		IsRaw:           gut.Ptr(true),
+		Title:           title,
+		Content:         &scrubbedContent,
+		ContentSha256:   &contentSha256,
+		CanonicalSource: nil,
+		SourceHost:      nil,
+		Pii:             piiCounts,
 	})
 	if err != nil {
 		return nil, gut.Err(false, "failed to create raw task", err)
 	}
 
+	// * fail exact duplicate without processing
+	if duplicateTask.Id != nil {
+		if err := querier.TaskDuplicateCreate(ctx, &psql.TaskDuplicateCreateParams{
+			TaskId:            task.Id,
+			DuplicateOfTaskId: duplicateTask.Id,
+			ChunkNo:           nil,
+			Score:             gut.Ptr(1.0),
+		}); err != nil {
+			return nil, gut.Err(false, "failed to record exact duplicate", err)
+		}
+
+		failedReason := fmt.Sprintf("exact duplicate #%s (content sha256)", gut.EncodeId(*duplicateTask.Id))
+		if _, err := querier.TaskUpdateFailed(ctx, &psql.TaskUpdateFailedParams{
+			Id:           task.Id,
+			FailedReason: &failedReason,
+			Title:        nil,
+			Content:      nil,
+			TokenCount:   nil,
+			ClaimedBy:    nil,
+		}); err != nil {
+			return nil, gut.Err(false, "failed to update exact duplicate task", err)
+		}
+		task.Status = gut.Ptr("failed")
+		task.FailedReason = &failedReason
+	}
+
 	return &task, nil
 }
@@ -1,6 +1,8 @@
 package taskProcedure
 
 import (
+	"backend/common/config"
+	"backend/common/extractor"
 	"backend/generate/psql"
 	"backend/type/common"
 	"context"
@@ -8,16 +10,20 @@ import (
 )
 
 type Server interface {
-	TaskCreate(ctx context.Context, querier psql.PQuerier, userId *uint64, uploadId *uint64, categoryName *string, taskType *string, source *string) (*psql.Task, *gut.ErrorInstance)
+	TaskCreate(ctx context.Context, querier psql.PQuerier, userId *uint64, uploadId *uint64, categoryName *string, taskType *string, source *string) (*psql.Task, bool, *gut.ErrorInstance)
 	TaskRawCreate(ctx context.Context, querier psql.PQuerier, userId *uint64, uploadId *uint64, categoryName *string, taskType *string, source *string, title *string, content *string) (*psql.Task, *gut.ErrorInstance)
 }
 
 type Service struct {
-	database common.Database
+	config    *config.Config
+	database  common.Database
+	extractor *extractor.Registry
 }
 
-func Serve(database common.Database) Server {
+func Serve(config *config.Config, database common.Database, extractor *extractor.Registry) Server {
 	return &Service{
-		database: database,
+		config:    config,
+		database:  database,
+		extractor: extractor,
 	}
 }
@@ -0,0 +1,110 @@
+package payload
+
+import (
+	"backend/type/common"
+	"time"
+)
+
+type AdminStatLatencyRequest struct {
+	Since *time.Time `json:"since" validate:"required"`
+}
+
+type AdminStatLatencyItem struct {
+	Type       *string                `json:"type"`
+	TaskCount  *uint64                `json:"taskCount"`
+	Extract    *AdminStatLatencyStage `json:"extract"`
+	TokenCount *AdminStatLatencyStage `json:"tokenCount"`
+	Split      *AdminStatLatencyStage `json:"split"`
+	Embedding  *AdminStatLatencyStage `json:"embedding"`
+	Search     *AdminStatLatencyStage `json:"search"`
+	Upsert     *AdminStatLatencyStage `json:"upsert"`
+	Total      *AdminStatLatencyStage `json:"total"`
+}
+
+type AdminStatLatencyStage struct {
+	P50 *float64 `json:"p50"`
+	P95 *float64 `json:"p95"`
+}
+
+type AdminStatLatencyResponse struct {
+	Types []*AdminStatLatencyItem `json:"types"`
+}
+
+type AdminExtractEndpointListRequest struct {
+	Since *time.Time `json:"since" validate:"required"`
+}
+
+type AdminExtractEndpointItem struct {
+	WorkerId            *string    `json:"workerId"`
+	Base                *string    `json:"base"`
+	State               *string    `json:"state"`
+	Capacity            *int32     `json:"capacity"`
+	Inflight            *int32     `json:"inflight"`
+	ConsecutiveFailures *int32     `json:"consecutiveFailures"`
+	SuccessCount        *uint64    `json:"successCount"`
+	FailureCount        *uint64    `json:"failureCount"`
+	SuccessRate         *float64   `json:"successRate"`
+	Latency             *uint64    `json:"latency"`
+	OpenedAt            *time.Time `json:"openedAt"`
+	LastError           *string    `json:"lastError"`
+	UpdatedAt           *time.Time `json:"updatedAt"`
+}
+
+type AdminExtractEndpointListResponse struct {
+	Endpoints []*AdminExtractEndpointItem `json:"endpoints"`
+}
+
+type AdminCategoryMismatchListRequest struct {
+	MinConfidence *float64 `json:"minConfidence" validate:"omitempty,gte=0,lte=1"`
+	common.Paginate
+}
+
+type AdminCategoryMismatchItem struct {
+	TaskId                *uint64    `json:"taskId"`
+	Title                 *string    `json:"title"`
+	Status                *string    `json:"status"`
+	CategoryId            *uint64    `json:"categoryId"`
+	CategoryName          *string    `json:"categoryName"`
+	SuggestedCategoryId   *uint64    `json:"suggestedCategoryId"`
+	SuggestedCategoryName *string    `json:"suggestedCategoryName"`
+	SuggestedConfidence   *float64   `json:"suggestedConfidence"`
+	SubmittedCategoryId   *uint64    `json:"submittedCategoryId"`
+	SubmittedCategoryName *string    `json:"submittedCategoryName"`
+	UpdatedAt             *time.Time `json:"updatedAt"`
+}
+
+type AdminCategoryMismatchListResponse struct {
+	Count *uint64                      `json:"count"`
+	Tasks []*AdminCategoryMismatchItem `json:"tasks"`
+}
+
+type AdminQueueItem struct {
+	UserId    *uint64    `json:"userId"`
+	Firstname *string    `json:"firstname"`
+	Lastname  *string    `json:"lastname"`
+	Weight    *float64   `json:"weight"`
+	Priority  *int32     `json:"priority"`
+	Queued    *int32     `json:"queued"`
+	Deficit   *float64   `json:"deficit"`
+	ServedAt  *time.Time `json:"servedAt"`
+}
+
+type AdminQueueListResponse struct {
+	Queues []*AdminQueueItem `json:"queues"`
+}
+
+type AdminQueuePriorityRequest struct {
+	CategoryId *uint64 `json:"categoryId" validate:"required"`
+	UserId     *uint64 `json:"userId" validate:"omitempty"`
+	Priority   *int32  `json:"priority" validate:"required"`
+}
+
+type AdminQueuePriorityResponse struct {
+	Count *int64 `json:"count"`
+}
+
+type AdminQueueWeightRequest struct {
+	CategoryId *uint64  `json:"categoryId" validate:"required_without=UserId"`
+	UserId     *uint64  `json:"userId" validate:"required_without=CategoryId"`
+	Weight     *float64 `json:"weight" validate:"required,gt=0"`
+}
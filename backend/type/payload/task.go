@@ -3,17 +3,20 @@ package payload
 import (
 	"backend/generate/psql"
 	"backend/type/common"
+	"encoding/json"
 	"time"
 )
 
 type TaskSubmitRequest struct {
 	Category *string `json:"category" validate:"required"`
-	Type     *string `json:"type" validate:"required,oneof=web doc youtube"`
+	Type     *string `json:"type" validate:"required"`
 	Source   *string `json:"source" validate:"required,url"`
 }
 
 type TaskSubmitResponse struct {
-	TaskId *uint64 `json:"taskId"`
+	TaskId   *uint64 `json:"taskId"`
+	Existing *bool   `json:"existing"`
+	Status   *string `json:"status"`
 }
 
 type TaskListRequest struct {
@@ -46,19 +49,21 @@ type OverviewRequest struct {
 }
 
 type OverviewHistoryItem struct {
-	Submitted *uint64 `json:"submitted"`
-	Pending   *uint64 `json:"pending"`
-	Completed *uint64 `json:"completed"`
-	Failed    *uint64 `json:"failed"`
+	Submitted  *uint64 `json:"submitted"`
+	Pending    *uint64 `json:"pending"`
+	Completed  *uint64 `json:"completed"`
+	Failed     *uint64 `json:"failed"`
+	LowQuality *uint64 `json:"lowQuality"`
 }
 
 type Overview struct {
-	TokenCount     *int32                   `json:"tokenCount"`
-	TotalCompleted *uint64                  `json:"totalCompleted"`
-	TotalFailed    *uint64                  `json:"totalFailed"`
-	TotalPending   *uint64                  `json:"totalPending"`
-	Histories      []*OverviewHistoryItem   `json:"histories"`
-	PoolTokens     []*PoolTokenCategoryItem `json:"poolTokens"`
+	TokenCount      *int32                   `json:"tokenCount"`
+	TotalCompleted  *uint64                  `json:"totalCompleted"`
+	TotalFailed     *uint64                  `json:"totalFailed"`
+	TotalPending    *uint64                  `json:"totalPending"`
+	TotalLowQuality *uint64                  `json:"totalLowQuality"`
+	Histories       []*OverviewHistoryItem   `json:"histories"`
+	PoolTokens      []*PoolTokenCategoryItem `json:"poolTokens"`
 }
 
 type PoolTokenCategoryItem struct {
@@ -76,22 +81,85 @@ type TaskDetailRequest struct {
 }
 
 type TaskDetailResponse struct {
-	Id           *uint64           `json:"id"`
-	UserId       *uint64           `json:"userId"`
-	UploadId     *uint64           `json:"uploadId"`
-	CategoryId   *uint64           `json:"categoryId"`
-	Type         *string           `json:"type"`
-	Source       *string           `json:"source"`
-	IsRaw        *bool             `json:"isRaw"`
-	Status       *string           `json:"status"`
-	FailedReason *string           `json:"failedReason"`
-	Title        *string           `json:"title"`
-	Content      *string           `json:"content"`
-	TokenCount   *int32            `json:"tokenCount"`
-	CreatedAt    *time.Time        `json:"createdAt"`
-	UpdatedAt    *time.Time        `json:"updatedAt"`
-	User         *UserListItem     `json:"user"`
-	Category     *TaskCategoryItem `json:"category"`
+	Id                  *uint64              `json:"id"`
+	UserId              *uint64              `json:"userId"`
+	UploadId            *uint64              `json:"uploadId"`
+	CategoryId          *uint64              `json:"categoryId"`
+	Type                *string              `json:"type"`
+	Source              *string              `json:"source"`
+	ResolvedSource      *string              `json:"resolvedSource"`
+	IsRaw               *bool                `json:"isRaw"`
+	Status              *string              `json:"status"`
+	FailedReason        *string              `json:"failedReason"`
+	Title               *string              `json:"title"`
+	Content             *string              `json:"content"`
+	TokenCount          *int32               `json:"tokenCount"`
+	Dedup               json.RawMessage      `json:"dedup"`
+	Extractor           *string              `json:"extractor"`
+	OcrPages            []int32              `json:"ocrPages"`
+	Quality             json.RawMessage      `json:"quality"`
+	Pii                 json.RawMessage      `json:"pii"`
+	Metadata            json.RawMessage      `json:"metadata"`
+	Priority            *int32               `json:"priority"`
+	SuggestedCategoryId *uint64              `json:"suggestedCategoryId"`
+	SuggestedConfidence *float64             `json:"suggestedConfidence"`
+	SubmittedCategoryId *uint64              `json:"submittedCategoryId"`
+	CreatedAt           *time.Time           `json:"createdAt"`
+	UpdatedAt           *time.Time           `json:"updatedAt"`
+	User                *UserListItem        `json:"user"`
+	Category            *TaskCategoryItem    `json:"category"`
+	Stat                *TaskStatItem        `json:"stat"`
+	Duplicates          []*TaskDuplicateItem `json:"duplicates"`
+}
+
+type TaskDuplicateItem struct {
+	TaskId  *string  `json:"taskId"`
+	Title   *string  `json:"title"`
+	Status  *string  `json:"status"`
+	ChunkNo *int32   `json:"chunkNo"`
+	Score   *float64 `json:"score"`
+}
+
+type TaskStatItem struct {
+	StartedAt          *time.Time      `json:"startedAt"`
+	FinishedAt         *time.Time      `json:"finishedAt"`
+	ExtractDuration    *uint64         `json:"extractDuration"`
+	TokenCountDuration *uint64         `json:"tokenCountDuration"`
+	SplitDuration      *uint64         `json:"splitDuration"`
+	EmbeddingDuration  *uint64         `json:"embeddingDuration"`
+	SearchDuration     *uint64         `json:"searchDuration"`
+	UpsertDuration     *uint64         `json:"upsertDuration"`
+	ChunkCount         *int32          `json:"chunkCount"`
+	Detail             json.RawMessage `json:"detail"`
+}
+
+type TaskChunkListRequest struct {
+	TaskId *uint64 `json:"taskId" validate:"required"`
+}
+
+type TaskChunkItem struct {
+	Id          *uint64             `json:"id"`
+	ChunkNo     *int32              `json:"chunkNo"`
+	StartOffset *int32              `json:"startOffset"`
+	EndOffset   *int32              `json:"endOffset"`
+	TokenCount  *int32              `json:"tokenCount"`
+	Hash        *string             `json:"hash"`
+	PointId     *string             `json:"pointId"`
+	Duplicate   *TaskChunkDuplicate `json:"duplicate"`
+}
+
+type TaskChunkDuplicate struct {
+	TaskId      *uint64  `json:"taskId"`
+	Title       *string  `json:"title"`
+	Status      *string  `json:"status"`
+	ChunkNo     *int32   `json:"chunkNo"`
+	StartOffset *int32   `json:"startOffset"`
+	EndOffset   *int32   `json:"endOffset"`
+	Score       *float64 `json:"score"`
+}
+
+type TaskChunkListResponse struct {
+	Chunks []*TaskChunkItem `json:"chunks"`
 }
 
 type TaskCategoryItem struct {
@@ -116,9 +184,16 @@ type TaskUploadListResponse struct {
 	Uploads []*TaskUploadItem `json:"uploads"`
 }
 
+type TaskSubmitBatchExistingItem struct {
+	TaskId *uint64 `json:"taskId"`
+	Status *string `json:"status"`
+}
+
 type TaskSubmitBatchResponse struct {
-	TasksCreated *int         `json:"tasksCreated"`
-	Tasks        []*psql.Task `json:"tasks"`
+	TasksCreated  *int                           `json:"tasksCreated"`
+	TasksExisting *int                           `json:"tasksExisting"`
+	Tasks         []*psql.Task                   `json:"tasks"`
+	ExistingTasks []*TaskSubmitBatchExistingItem `json:"existingTasks"`
 }
 
 type UserListItem struct {
@@ -129,6 +204,7 @@ type UserListItem struct {
 	Email     *string    `json:"email"`
 	PhotoUrl  *string    `json:"photoUrl"`
 	IsAdmin   *bool      `json:"isAdmin"`
+	Weight    *float64   `json:"weight"`
 	CreatedAt *time.Time `json:"createdAt"`
 	UpdatedAt *time.Time `json:"updatedAt"`
 }
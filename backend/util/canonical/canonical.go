@@ -0,0 +1,129 @@
+package canonical
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"gbraid":  true,
+	"wbraid":  true,
+	"msclkid": true,
+	"yclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"ref_src": true,
+	"_ga":     true,
+	"_gl":     true,
+	"si":      true,
+	"feature": true,
+}
+
+var youtubeIdPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+var driveIdPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{10,}$`)
+
+func Url(raw string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("url %s has no host", raw)
+	}
+
+	// * lowercase host, drop default port and www prefix
+	host := strings.ToLower(parsed.Hostname())
+	host = strings.TrimSuffix(host, ".")
+	host = strings.TrimPrefix(host, "www.")
+	if port := parsed.Port(); port != "" && port != "80" && port != "443" {
+		host = host + ":" + port
+	}
+
+	// * normalize known document hosts by their ids
+	if canonical, ok := youtube(host, parsed); ok {
+		return canonical, nil
+	}
+	if canonical, ok := drive(host, parsed); ok {
+		return canonical, nil
+	}
+
+	// * resolve trailing slashes
+	path := parsed.EscapedPath()
+	path = strings.TrimRight(path, "/")
+	if path == "" {
+		path = "/"
+	}
+
+	// * strip tracking params, remaining are sorted by encode
+	query := parsed.Query()
+	for key := range query {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") || trackingParams[strings.ToLower(key)] {
+			query.Del(key)
+		}
+	}
+	canonical := "https://" + host + path
+	if len(query) > 0 {
+		canonical += "?" + query.Encode()
+	}
+
+	return canonical, nil
+}
+
+func youtube(host string, parsed *url.URL) (string, bool) {
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	id := ""
+
+	switch host {
+	case "youtu.be":
+		id = segments[0]
+	case "youtube.com", "m.youtube.com", "music.youtube.com", "youtube-nocookie.com":
+		switch segments[0] {
+		case "watch":
+			id = parsed.Query().Get("v")
+		case "shorts", "embed", "live", "v":
+			if len(segments) > 1 {
+				id = segments[1]
+			}
+		}
+	default:
+		return "", false
+	}
+
+	if !youtubeIdPattern.MatchString(id) {
+		return "", false
+	}
+
+	return "https://youtube.com/watch?v=" + id, true
+}
+
+func drive(host string, parsed *url.URL) (string, bool) {
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+
+	switch host {
+	case "drive.google.com":
+		// * file/d/{id}/view, open?id={id} and uc?id={id}
+		id := parsed.Query().Get("id")
+		if len(segments) > 2 && segments[0] == "file" && segments[1] == "d" {
+			id = segments[2]
+		}
+		if !driveIdPattern.MatchString(id) {
+			return "", false
+		}
+		return "https://drive.google.com/file/d/" + id, true
+	case "docs.google.com":
+		// * {document,spreadsheets,presentation}/d/{id}/edit
+		if len(segments) > 2 && segments[1] == "d" && driveIdPattern.MatchString(segments[2]) {
+			return "https://docs.google.com/" + segments[0] + "/d/" + segments[2], true
+		}
+		return "", false
+	default:
+		return "", false
+	}
+}
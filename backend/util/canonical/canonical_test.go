@@ -0,0 +1,48 @@
+package canonical
+
+import "testing"
+
+func TestUrl(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected string
+		invalid  bool
+	}{
+		{name: "lowercase host and drop www", raw: "http://WWW.Example.COM/Article", expected: "https://example.com/Article"},
+		{name: "drop default port", raw: "https://example.com:443/a", expected: "https://example.com/a"},
+		{name: "keep custom port", raw: "https://example.com:8080/a", expected: "https://example.com:8080/a"},
+		{name: "trim trailing slash", raw: "https://example.com/a/b/", expected: "https://example.com/a/b"},
+		{name: "root path", raw: "https://example.com", expected: "https://example.com/"},
+		{name: "strip tracking params", raw: "https://example.com/a?utm_source=x&fbclid=y&id=1", expected: "https://example.com/a?id=1"},
+		{name: "sort remaining params", raw: "https://example.com/a?b=2&a=1", expected: "https://example.com/a?a=1&b=2"},
+		{name: "drop fragment", raw: "https://example.com/a#section", expected: "https://example.com/a"},
+		{name: "youtube short link", raw: "https://youtu.be/dQw4w9WgXcQ?si=abc", expected: "https://youtube.com/watch?v=dQw4w9WgXcQ"},
+		{name: "youtube watch", raw: "https://m.youtube.com/watch?v=dQw4w9WgXcQ&t=10", expected: "https://youtube.com/watch?v=dQw4w9WgXcQ"},
+		{name: "youtube shorts", raw: "https://www.youtube.com/shorts/dQw4w9WgXcQ", expected: "https://youtube.com/watch?v=dQw4w9WgXcQ"},
+		{name: "youtube without video id", raw: "https://youtube.com/feed/trending", expected: "https://youtube.com/feed/trending"},
+		{name: "drive file view", raw: "https://drive.google.com/file/d/1AbCdEfGhIjK/view?usp=sharing", expected: "https://drive.google.com/file/d/1AbCdEfGhIjK"},
+		{name: "drive open id", raw: "https://drive.google.com/open?id=1AbCdEfGhIjK", expected: "https://drive.google.com/file/d/1AbCdEfGhIjK"},
+		{name: "docs edit", raw: "https://docs.google.com/document/d/1AbCdEfGhIjK/edit#heading", expected: "https://docs.google.com/document/d/1AbCdEfGhIjK"},
+		{name: "missing host", raw: "example.com/a", invalid: true},
+		{name: "unparsable", raw: "http://[::1", invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			canonical, err := Url(test.raw)
+			if test.invalid {
+				if err == nil {
+					t.Fatalf("expected error, got %s", canonical)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if canonical != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, canonical)
+			}
+		})
+	}
+}
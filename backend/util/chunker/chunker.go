@@ -0,0 +1,168 @@
+package chunker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+const (
+	StrategyRecursive = "recursive"
+	StrategyToken     = "token"
+	StrategyMarkdown  = "markdown"
+	StrategySentence  = "sentence"
+)
+
+type Strategy struct {
+	Name     *string `yaml:"name" json:"name" validate:"required,oneof=recursive token markdown sentence"`
+	Size     *int    `yaml:"size" json:"size" validate:"required,gte=1"`
+	Overlap  *int    `yaml:"overlap" json:"overlap" validate:"required,gte=0"`
+	Encoding *string `yaml:"encoding" json:"encoding,omitempty" validate:"omitempty"`
+}
+
+type Chunk struct {
+	No         int
+	Text       string
+	Start      *int
+	End        *int
+	TokenCount *int
+	Hash       string
+}
+
+type Chunker struct {
+	strategy *Strategy
+	encoding *tiktoken.Tiktoken
+	splitter textsplitter.TextSplitter
+}
+
+func init() {
+	// * load bpe ranks embedded in binary instead of downloading them at runtime
+	tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+}
+
+func New(strategy *Strategy) (*Chunker, error) {
+	chunker := &Chunker{
+		strategy: strategy,
+		encoding: nil,
+		splitter: nil,
+	}
+
+	// * resolve tokenizer, sizes are measured in tokens when encoding is set
+	// * tiktoken encodings differ from the embedding model tokenizer, so token counts are approximate
+	if strategy.Encoding != nil {
+		encoding, err := tiktoken.GetEncoding(*strategy.Encoding)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load encoding %s: %w", *strategy.Encoding, err)
+		}
+		chunker.encoding = encoding
+	} else if *strategy.Name == StrategyToken {
+		return nil, fmt.Errorf("token strategy requires encoding")
+	}
+
+	options := []textsplitter.Option{
+		textsplitter.WithChunkSize(*strategy.Size),
+		textsplitter.WithChunkOverlap(*strategy.Overlap),
+		textsplitter.WithLenFunc(chunker.Count),
+	}
+
+	switch *strategy.Name {
+	case StrategyRecursive, StrategyToken:
+		chunker.splitter = textsplitter.NewRecursiveCharacter(append(options,
+			textsplitter.WithSeparators([]string{
+				"\n\n", // * paragraphs first
+				"\n",   // * then newlines
+				". ",   // * then sentences
+				", ",   // * then commas
+				" ",    // * then spaces
+				"",     // * then chars
+			}),
+		)...)
+	case StrategyMarkdown:
+		chunker.splitter = textsplitter.NewMarkdownTextSplitter(append(options,
+			textsplitter.WithHeadingHierarchy(true),
+			textsplitter.WithCodeBlocks(true),
+		)...)
+	case StrategySentence:
+		chunker.splitter = textsplitter.NewRecursiveCharacter(append(options,
+			textsplitter.WithKeepSeparator(true),
+			textsplitter.WithSeparators([]string{
+				". ", // * sentences first
+				"? ", // * then questions
+				"! ", // * then exclamations
+				"\n", // * then newlines
+				" ",  // * then spaces, which also delimit thai sentences
+				"",   // * then chars
+			}),
+		)...)
+	default:
+		return nil, fmt.Errorf("unknown chunking strategy %s", *strategy.Name)
+	}
+
+	return chunker, nil
+}
+
+func (r *Chunker) Strategy() *Strategy {
+	return r.strategy
+}
+
+func (r *Chunker) Split(text string) ([]string, error) {
+	return r.splitter.SplitText(text)
+}
+
+func (r *Chunker) Chunks(text string) ([]*Chunk, error) {
+	texts, err := r.splitter.SplitText(text)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]*Chunk, 0, len(texts))
+	cursor := 0
+	runeCursor := 0
+	for i, chunkText := range texts {
+		hash := sha256.Sum256([]byte(chunkText))
+		chunk := &Chunk{
+			No:         i,
+			Text:       chunkText,
+			Start:      nil,
+			End:        nil,
+			TokenCount: nil,
+			Hash:       hex.EncodeToString(hash[:]),
+		}
+
+		// * count tokens only when sizes are measured in tokens
+		if r.encoding != nil {
+			tokenCount := r.Count(chunkText)
+			chunk.TokenCount = &tokenCount
+		}
+
+		// * locate chunk in rune offsets, chunks rewritten by splitter such as heading hierarchy are left unlocated
+		if index := strings.Index(text[cursor:], chunkText); index >= 0 {
+			start := runeCursor + utf8.RuneCountInString(text[cursor:cursor+index])
+			end := start + utf8.RuneCountInString(chunkText)
+			chunk.Start = &start
+			chunk.End = &end
+
+			// * advance past chunk start only, next chunk may overlap
+			_, size := utf8.DecodeRuneInString(text[cursor+index:])
+			runeCursor = start + 1
+			cursor += index + size
+		}
+
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks, nil
+}
+
+func (r *Chunker) Count(text string) int {
+	if r.encoding == nil {
+		return utf8.RuneCountInString(text)
+	}
+	return len(r.encoding.EncodeOrdinary(text))
+}
@@ -0,0 +1,79 @@
+package chunker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bsthun/gut"
+)
+
+func TestChunksOffsets(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy *Strategy
+		text     string
+	}{
+		{
+			name:     "recursive ascii",
+			strategy: &Strategy{Name: gut.Ptr(StrategyRecursive), Size: gut.Ptr(40), Overlap: gut.Ptr(10), Encoding: nil},
+			text:     strings.Repeat("The quick brown fox jumps over the lazy dog. ", 12),
+		},
+		{
+			name:     "recursive thai",
+			strategy: &Strategy{Name: gut.Ptr(StrategyRecursive), Size: gut.Ptr(30), Overlap: gut.Ptr(5), Encoding: nil},
+			text:     strings.Repeat("ภาษาไทยไม่มีการเว้นวรรคระหว่างคำ แต่เว้นวรรคระหว่างประโยค\n", 6),
+		},
+		{
+			name:     "sentence repeated text",
+			strategy: &Strategy{Name: gut.Ptr(StrategySentence), Size: gut.Ptr(25), Overlap: gut.Ptr(0), Encoding: nil},
+			text:     strings.Repeat("Same sentence here. ", 10),
+		},
+		{
+			name:     "token paragraphs",
+			strategy: &Strategy{Name: gut.Ptr(StrategyToken), Size: gut.Ptr(16), Overlap: gut.Ptr(4), Encoding: gut.Ptr("cl100k_base")},
+			text:     strings.Repeat("First paragraph with some words.\n\nSecond paragraph, with émojis 🙂 and accents.\n\n", 5),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chunker, err := New(test.strategy)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			chunks, err := chunker.Chunks(test.text)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(chunks) < 2 {
+				t.Fatalf("expected multiple chunks, got %d", len(chunks))
+			}
+
+			// * each located chunk must slice back to its text in rune offsets, in document order
+			runes := []rune(test.text)
+			previous := -1
+			for _, chunk := range chunks {
+				if chunk.Start == nil || chunk.End == nil {
+					t.Fatalf("chunk %d is not located", chunk.No)
+				}
+				if *chunk.Start <= previous {
+					t.Errorf("chunk %d starts at %d, not after previous start %d", chunk.No, *chunk.Start, previous)
+				}
+				if located := string(runes[*chunk.Start:*chunk.End]); located != chunk.Text {
+					t.Errorf("chunk %d located as %q, expected %q", chunk.No, located, chunk.Text)
+				}
+				if (chunk.TokenCount != nil) != (test.strategy.Encoding != nil) {
+					t.Errorf("chunk %d token count presence does not match encoding", chunk.No)
+				}
+				previous = *chunk.Start
+			}
+		})
+	}
+}
+
+func TestNewRejectsTokenWithoutEncoding(t *testing.T) {
+	_, err := New(&Strategy{Name: gut.Ptr(StrategyToken), Size: gut.Ptr(16), Overlap: gut.Ptr(0), Encoding: nil})
+	if err == nil {
+		t.Fatal("expected error for token strategy without encoding")
+	}
+}
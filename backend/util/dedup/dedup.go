@@ -0,0 +1,84 @@
+package dedup
+
+import (
+	"strconv"
+
+	qd "github.com/qdrant/go-client/qdrant"
+)
+
+const (
+	ScopeType     = "type"
+	ScopeCategory = "category"
+	ScopeGlobal   = "global"
+)
+
+type Policy struct {
+	Threshold *float64 `yaml:"threshold" json:"threshold" validate:"omitempty,gt=0,lte=1"`
+	Ratio     *float64 `yaml:"ratio" json:"ratio" validate:"omitempty,gte=0,lte=1"`
+	Scope     *string  `yaml:"scope" json:"scope" validate:"omitempty,oneof=type category global"`
+}
+
+func (r *Policy) Merge(override *Policy) *Policy {
+	policy := &Policy{
+		Threshold: r.Threshold,
+		Ratio:     r.Ratio,
+		Scope:     r.Scope,
+	}
+	if override == nil {
+		return policy
+	}
+	if override.Threshold != nil {
+		policy.Threshold = override.Threshold
+	}
+	if override.Ratio != nil {
+		policy.Ratio = override.Ratio
+	}
+	if override.Scope != nil {
+		policy.Scope = override.Scope
+	}
+	return policy
+}
+
+func (r *Policy) Duplicate(duplicateCount int, chunkCount int) bool {
+	return float64(duplicateCount) > *r.Ratio*float64(chunkCount)
+}
+
+func (r *Policy) Filter(taskId uint64, taskType string, categoryId uint64) *qd.Filter {
+	filter := &qd.Filter{
+		Must: nil,
+		MustNot: []*qd.Condition{
+			keyword("taskId", strconv.FormatUint(taskId, 10)),
+		},
+	}
+
+	// * narrow search to scope of policy
+	switch *r.Scope {
+	case ScopeType:
+		filter.Must = append(filter.Must, keyword("type", taskType))
+	case ScopeCategory:
+		// * points upserted before category payload count as in scope
+		filter.Must = append(filter.Must, qd.NewFilterAsCondition(&qd.Filter{
+			Should: []*qd.Condition{
+				keyword("categoryId", strconv.FormatUint(categoryId, 10)),
+				qd.NewIsEmpty("categoryId"),
+			},
+		}))
+	}
+
+	return filter
+}
+
+func keyword(key string, value string) *qd.Condition {
+	return &qd.Condition{
+		ConditionOneOf: &qd.Condition_Field{
+			Field: &qd.FieldCondition{
+				Key: key,
+				Match: &qd.Match{
+					MatchValue: &qd.Match_Keyword{
+						Keyword: value,
+					},
+				},
+			},
+		},
+	}
+}
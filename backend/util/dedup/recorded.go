@@ -0,0 +1,19 @@
+package dedup
+
+import (
+	"backend/generate/psql"
+	"encoding/json"
+)
+
+func Recorded(task *psql.Task) *Policy {
+	// * parse policy recorded when task was processed
+	policy := new(Policy)
+	if err := json.Unmarshal(task.Dedup, policy); err != nil {
+		return nil
+	}
+	if policy.Threshold == nil || policy.Ratio == nil || policy.Scope == nil {
+		return nil
+	}
+
+	return policy
+}
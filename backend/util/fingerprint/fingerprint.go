@@ -0,0 +1,33 @@
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// * ascii whitespace only, kept in sync with backfill in add_task_content_sha256 migration
+const whitespace = " \t\n\r\f\v"
+
+func Normalize(text string) string {
+	builder := new(strings.Builder)
+	builder.Grow(len(text))
+	space := false
+	for _, r := range strings.Trim(text, whitespace) {
+		if strings.ContainsRune(whitespace, r) {
+			space = true
+			continue
+		}
+		if space {
+			builder.WriteByte(' ')
+			space = false
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+func ContentSha256(text string) string {
+	hash := sha256.Sum256([]byte(Normalize(text)))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,82 @@
+package metadata
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+func FromHtml(doc *html.Node) *Metadata {
+	metadata := new(Metadata)
+	metas := make(map[string]string)
+	documentTitle := ""
+
+	// * collect meta tags by name, property and itemprop, first occurrence wins
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.ElementNode {
+			switch node.DataAtom {
+			case atom.Html:
+				if lang := attr(node, "lang"); lang != "" {
+					metadata.Language = Clean(lang, 32)
+				}
+			case atom.Title:
+				if documentTitle == "" && node.FirstChild != nil {
+					documentTitle = node.FirstChild.Data
+				}
+			case atom.Meta:
+				content := attr(node, "content")
+				for _, key := range []string{attr(node, "name"), attr(node, "property"), attr(node, "itemprop")} {
+					key = strings.ToLower(strings.TrimSpace(key))
+					if key == "" || content == "" {
+						continue
+					}
+					if _, ok := metas[key]; !ok {
+						metas[key] = content
+					}
+				}
+			case atom.Link:
+				if metadata.CanonicalUrl == nil && strings.EqualFold(attr(node, "rel"), "canonical") {
+					metadata.CanonicalUrl = Clean(attr(node, "href"), 2048)
+				}
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	metadata.Title = first(255, metas["og:title"], metas["twitter:title"], documentTitle)
+	metadata.Author = first(255, metas["author"], metas["article:author"], metas["byl"], metas["dc.creator"])
+	metadata.PublishedAt = first(64, metas["article:published_time"], metas["datepublished"], metas["publishdate"], metas["pubdate"], metas["dc.date"], metas["date"])
+	metadata.Description = first(1024, metas["description"], metas["og:description"], metas["twitter:description"])
+	metadata.SiteName = first(255, metas["og:site_name"], metas["application-name"])
+	if metadata.CanonicalUrl == nil {
+		metadata.CanonicalUrl = first(2048, metas["og:url"])
+	}
+	if metadata.Language == nil {
+		metadata.Language = first(32, metas["og:locale"], metas["content-language"], metas["language"])
+	}
+
+	return metadata
+}
+
+func first(length int, values ...string) *string {
+	for _, value := range values {
+		if cleaned := Clean(value, length); cleaned != nil {
+			return cleaned
+		}
+	}
+	return nil
+}
+
+func attr(node *html.Node, key string) string {
+	for _, attribute := range node.Attr {
+		if attribute.Key == key {
+			return attribute.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package metadata
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	TitleSourceExtractor = "extractor"
+	TitleSourceSubmitted = "submitted"
+	TitleSourceGenerated = "generated"
+	TitleSourceContent   = "content"
+)
+
+type Metadata struct {
+	Title        *string `json:"title"`
+	TitleSource  *string `json:"titleSource"`
+	Author       *string `json:"author"`
+	PublishedAt  *string `json:"publishedAt"`
+	Description  *string `json:"description"`
+	CanonicalUrl *string `json:"canonicalUrl"`
+	Language     *string `json:"language"`
+	SiteName     *string `json:"siteName"`
+}
+
+func Clean(value string, length int) *string {
+	// * collapse whitespace and cap length in runes
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return nil
+	}
+	if utf8.RuneCountInString(value) > length {
+		value = string([]rune(value)[:length])
+	}
+	return &value
+}
@@ -0,0 +1,50 @@
+package metadata
+
+import (
+	"regexp"
+	"time"
+)
+
+var pdfDatePattern = regexp.MustCompile(`^D?:?(\d{4})(\d{2})?(\d{2})?(\d{2})?(\d{2})?(\d{2})?([Zz+\-])?(\d{2})?'?(\d{2})?`)
+
+func FromPdf(info map[string]string) *Metadata {
+	metadata := new(Metadata)
+	metadata.Title = Clean(info["title"], 255)
+	metadata.Author = Clean(info["author"], 255)
+	metadata.Description = Clean(info["subject"], 1024)
+	metadata.PublishedAt = pdfDate(info["creationDate"])
+
+	return metadata
+}
+
+func pdfDate(value string) *string {
+	// * pdf dates are formatted as D:YYYYMMDDHHmmSSOHH'mm'
+	match := pdfDatePattern.FindStringSubmatch(value)
+	if match == nil {
+		return Clean(value, 64)
+	}
+	parts := make([]string, 0, 6)
+	for i, fallback := range []string{"", "01", "01", "00", "00", "00"} {
+		if match[i+1] == "" {
+			parts = append(parts, fallback)
+		} else {
+			parts = append(parts, match[i+1])
+		}
+	}
+
+	zone := "Z"
+	if match[7] == "+" || match[7] == "-" {
+		minute := match[9]
+		if minute == "" {
+			minute = "00"
+		}
+		zone = match[7] + match[8] + ":" + minute
+	}
+
+	parsed, err := time.Parse(time.RFC3339, parts[0]+"-"+parts[1]+"-"+parts[2]+"T"+parts[3]+":"+parts[4]+":"+parts[5]+zone)
+	if err != nil {
+		return Clean(value, 64)
+	}
+	formatted := parsed.Format(time.RFC3339)
+	return &formatted
+}
@@ -0,0 +1,126 @@
+package pii
+
+import (
+	"net"
+	"regexp"
+	"strings"
+)
+
+type detector struct {
+	piiType string
+	pattern *regexp.Regexp
+	valid   func(match string) bool
+}
+
+// * emails and checksum validated numbers are redacted before looser phone pattern
+var detectors = []*detector{
+	{
+		piiType: TypeEmail,
+		pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+		valid:   nil,
+	},
+	{
+		piiType: TypeNationalId,
+		pattern: regexp.MustCompile(`\b\d[ -]?\d{4}[ -]?\d{5}[ -]?\d{2}[ -]?\d\b`),
+		valid:   validNationalId,
+	},
+	{
+		piiType: TypeCreditCard,
+		pattern: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		valid:   validLuhn,
+	},
+	{
+		piiType: TypeIp,
+		pattern: regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b|\b(?:[0-9A-Fa-f]{0,4}:){2,7}[0-9A-Fa-f]{0,4}\b`),
+		valid:   validIp,
+	},
+	{
+		piiType: TypePhone,
+		pattern: regexp.MustCompile(`(?:\+66[ -]?|\b0)(?:[689]\d|[2-7])[ -]?\d{3}[ -]?\d{3,4}\b|\+\d{1,3}(?:[ -]?\(?\d{1,4}\)?){2,5}\b`),
+		valid:   validPhone,
+	},
+}
+
+func Scrub(text string, policy *Policy) (string, map[string]int) {
+	counts := make(map[string]int)
+	if policy == nil || policy.Enabled == nil || !*policy.Enabled {
+		return text, counts
+	}
+
+	enabled := make(map[string]bool)
+	for _, piiType := range policy.Types {
+		enabled[*piiType] = true
+	}
+
+	// * replace detected values with typed placeholders
+	for _, detector := range detectors {
+		if !enabled[detector.piiType] {
+			continue
+		}
+		placeholder := "[" + strings.ToUpper(detector.piiType) + "]"
+		counts[detector.piiType] = 0
+		text = detector.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if detector.valid != nil && !detector.valid(match) {
+				return match
+			}
+			counts[detector.piiType]++
+			return placeholder
+		})
+	}
+
+	return text, counts
+}
+
+func digits(match string) []int {
+	values := make([]int, 0, len(match))
+	for _, char := range match {
+		if char >= '0' && char <= '9' {
+			values = append(values, int(char-'0'))
+		}
+	}
+	return values
+}
+
+func validNationalId(match string) bool {
+	values := digits(match)
+	if len(values) != 13 {
+		return false
+	}
+
+	// * check digit is mod 11 weighted sum of first twelve digits
+	sum := 0
+	for i := 0; i < 12; i++ {
+		sum += values[i] * (13 - i)
+	}
+	return (11-sum%11)%10 == values[12]
+}
+
+func validLuhn(match string) bool {
+	values := digits(match)
+	if len(values) < 13 || len(values) > 19 {
+		return false
+	}
+
+	sum := 0
+	for i := len(values) - 1; i >= 0; i-- {
+		value := values[i]
+		if (len(values)-1-i)%2 == 1 {
+			value *= 2
+			if value > 9 {
+				value -= 9
+			}
+		}
+		sum += value
+	}
+	return sum%10 == 0
+}
+
+func validIp(match string) bool {
+	// * colon separated candidates must parse as ipv6 address
+	return strings.Contains(match, ".") || (strings.Count(match, ":") >= 2 && net.ParseIP(match) != nil)
+}
+
+func validPhone(match string) bool {
+	count := len(digits(match))
+	return count >= 9 && count <= 15
+}
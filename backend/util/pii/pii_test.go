@@ -0,0 +1,124 @@
+package pii
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/bsthun/gut"
+)
+
+func TestScrub(t *testing.T) {
+	all := &Policy{
+		Enabled: gut.Ptr(true),
+		Types:   []*string{gut.Ptr(TypeEmail), gut.Ptr(TypePhone), gut.Ptr(TypeNationalId), gut.Ptr(TypeCreditCard), gut.Ptr(TypeIp)},
+	}
+	tests := []struct {
+		name     string
+		text     string
+		policy   *Policy
+		expected string
+		counts   map[string]int
+	}{
+		{
+			name:     "disabled policy",
+			text:     "mail me at someone@example.com",
+			policy:   &Policy{Enabled: gut.Ptr(false), Types: all.Types},
+			expected: "mail me at someone@example.com",
+			counts:   map[string]int{},
+		},
+		{
+			name:     "nil policy",
+			text:     "mail me at someone@example.com",
+			policy:   nil,
+			expected: "mail me at someone@example.com",
+			counts:   map[string]int{},
+		},
+		{
+			name:     "email",
+			text:     "mail me at some.one+tag@mail.example.co.th today",
+			policy:   all,
+			expected: "mail me at [EMAIL] today",
+			counts:   map[string]int{TypeEmail: 1, TypePhone: 0, TypeNationalId: 0, TypeCreditCard: 0, TypeIp: 0},
+		},
+		{
+			name:     "valid national id",
+			text:     "id 1-1017-00207-43-9 on file",
+			policy:   all,
+			expected: "id [NATIONAL_ID] on file",
+			counts:   map[string]int{TypeEmail: 0, TypePhone: 0, TypeNationalId: 1, TypeCreditCard: 0, TypeIp: 0},
+		},
+		{
+			name:     "valid credit card",
+			text:     "card 4111 1111 1111 1111 charged",
+			policy:   all,
+			expected: "card [CREDIT_CARD] charged",
+			counts:   map[string]int{TypeEmail: 0, TypePhone: 0, TypeNationalId: 0, TypeCreditCard: 1, TypeIp: 0},
+		},
+		{
+			name:     "invalid luhn is kept",
+			text:     "order 4111111111111112 shipped",
+			policy:   &Policy{Enabled: gut.Ptr(true), Types: []*string{gut.Ptr(TypeCreditCard)}},
+			expected: "order 4111111111111112 shipped",
+			counts:   map[string]int{TypeCreditCard: 0},
+		},
+		{
+			name:     "thai mobile phone",
+			text:     "call 081-234-5678 now",
+			policy:   all,
+			expected: "call [PHONE] now",
+			counts:   map[string]int{TypeEmail: 0, TypePhone: 1, TypeNationalId: 0, TypeCreditCard: 0, TypeIp: 0},
+		},
+		{
+			name:     "ipv4 and ipv6",
+			text:     "from 192.168.1.10 and 2001:db8::1",
+			policy:   all,
+			expected: "from [IP] and [IP]",
+			counts:   map[string]int{TypeEmail: 0, TypePhone: 0, TypeNationalId: 0, TypeCreditCard: 0, TypeIp: 2},
+		},
+		{
+			name:     "only enabled types",
+			text:     "someone@example.com 192.168.1.10",
+			policy:   &Policy{Enabled: gut.Ptr(true), Types: []*string{gut.Ptr(TypeIp)}},
+			expected: "someone@example.com [IP]",
+			counts:   map[string]int{TypeIp: 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scrubbed, counts := Scrub(test.text, test.policy)
+			if scrubbed != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, scrubbed)
+			}
+			if !maps.Equal(counts, test.counts) {
+				t.Errorf("expected counts %v, got %v", test.counts, counts)
+			}
+		})
+	}
+}
+
+func TestPolicyMerge(t *testing.T) {
+	base := &Policy{Enabled: gut.Ptr(true), Types: []*string{gut.Ptr(TypeEmail)}}
+	tests := []struct {
+		name     string
+		override *Policy
+		enabled  bool
+		types    int
+	}{
+		{name: "no override", override: nil, enabled: true, types: 1},
+		{name: "disable only", override: &Policy{Enabled: gut.Ptr(false), Types: nil}, enabled: false, types: 1},
+		{name: "replace types", override: &Policy{Enabled: nil, Types: []*string{gut.Ptr(TypeIp), gut.Ptr(TypePhone)}}, enabled: true, types: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy := base.Merge(test.override)
+			if *policy.Enabled != test.enabled {
+				t.Errorf("expected enabled %v, got %v", test.enabled, *policy.Enabled)
+			}
+			if len(policy.Types) != test.types {
+				t.Errorf("expected %d types, got %d", test.types, len(policy.Types))
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package pii
+
+const (
+	TypeEmail      = "email"
+	TypePhone      = "phone"
+	TypeNationalId = "national_id"
+	TypeCreditCard = "credit_card"
+	TypeIp         = "ip"
+)
+
+type Policy struct {
+	Enabled *bool     `yaml:"enabled" json:"enabled" validate:"omitempty"`
+	Types   []*string `yaml:"types" json:"types" validate:"omitempty,dive,oneof=email phone national_id credit_card ip"`
+}
+
+func (r *Policy) Merge(override *Policy) *Policy {
+	policy := &Policy{
+		Enabled: r.Enabled,
+		Types:   r.Types,
+	}
+	if override == nil {
+		return policy
+	}
+	if override.Enabled != nil {
+		policy.Enabled = override.Enabled
+	}
+	if override.Types != nil {
+		policy.Types = override.Types
+	}
+	return policy
+}
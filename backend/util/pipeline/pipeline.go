@@ -0,0 +1,28 @@
+package pipeline
+
+import (
+	"sync"
+)
+
+func Run[T any](in <-chan T, out chan<- T, concurrency int, handle func(T) bool) {
+	wg := new(sync.WaitGroup)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// * forward handled item to next stage, send blocks while next queue is full
+			for item := range in {
+				if handle(item) {
+					out <- item
+				}
+			}
+		}()
+	}
+
+	// * close next stage once input is drained
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+}
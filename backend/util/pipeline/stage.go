@@ -0,0 +1,23 @@
+package pipeline
+
+type Stage struct {
+	Concurrency *int `yaml:"concurrency" validate:"omitempty,gte=1"`
+	Queue       *int `yaml:"queue" validate:"omitempty,gte=0"`
+}
+
+func (r *Stage) Merge(override *Stage) *Stage {
+	stage := &Stage{
+		Concurrency: r.Concurrency,
+		Queue:       r.Queue,
+	}
+	if override == nil {
+		return stage
+	}
+	if override.Concurrency != nil {
+		stage.Concurrency = override.Concurrency
+	}
+	if override.Queue != nil {
+		stage.Queue = override.Queue
+	}
+	return stage
+}
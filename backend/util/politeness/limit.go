@@ -0,0 +1,22 @@
+package politeness
+
+import (
+	"encoding/json"
+)
+
+type Limit struct {
+	Concurrency *int32 `yaml:"concurrency" json:"concurrency,omitempty" validate:"omitempty,gte=1"`
+	Rate        *int32 `yaml:"rate" json:"rate,omitempty" validate:"omitempty,gte=1"`
+}
+
+func Overrides(limits map[string]*Limit) json.RawMessage {
+	// * host limits are looked up by host inside claim query
+	if len(limits) == 0 {
+		return json.RawMessage("{}")
+	}
+	overrides, err := json.Marshal(limits)
+	if err != nil {
+		return json.RawMessage("{}")
+	}
+	return overrides
+}
@@ -0,0 +1,101 @@
+package politeness
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/temoto/robotstxt"
+)
+
+type Robots struct {
+	client    *http.Client
+	userAgent string
+	ttl       time.Duration
+	mu        sync.Mutex
+	entries   map[string]*robotsEntry
+}
+
+type robotsEntry struct {
+	data      *robotstxt.RobotsData
+	fetchedAt time.Time
+}
+
+func NewRobots(userAgent string, ttl time.Duration) *Robots {
+	return &Robots{
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		userAgent: userAgent,
+		ttl:       ttl,
+		mu:        sync.Mutex{},
+		entries:   make(map[string]*robotsEntry),
+	}
+}
+
+func (r *Robots) Allowed(ctx context.Context, source string) (bool, error) {
+	parsed, err := url.Parse(source)
+	if err != nil {
+		return false, err
+	}
+
+	// * only http sources are governed by robots.txt
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return true, nil
+	}
+
+	data, err := r.get(ctx, parsed)
+	if err != nil {
+		return false, err
+	}
+
+	path := parsed.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if parsed.RawQuery != "" {
+		path += "?" + parsed.RawQuery
+	}
+
+	return data.TestAgent(path, r.userAgent), nil
+}
+
+func (r *Robots) get(ctx context.Context, parsed *url.URL) (*robotstxt.RobotsData, error) {
+	origin := parsed.Scheme + "://" + parsed.Host
+
+	// * reuse cached robots.txt of origin
+	r.mu.Lock()
+	entry, ok := r.entries[origin]
+	r.mu.Unlock()
+	if ok && time.Since(entry.fetchedAt) < r.ttl {
+		return entry.data, nil
+	}
+
+	// * fetch robots.txt, status code semantics are handled by parser
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("User-Agent", r.userAgent)
+	response, err := r.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	data, err := robotstxt.FromResponse(response)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.entries[origin] = &robotsEntry{
+		data:      data,
+		fetchedAt: time.Now(),
+	}
+	r.mu.Unlock()
+
+	return data, nil
+}
@@ -0,0 +1,74 @@
+package quality
+
+import (
+	"fmt"
+)
+
+const (
+	ActionOff  = "off"
+	ActionFlag = "flag"
+	ActionFail = "fail"
+)
+
+type Policy struct {
+	Action               *string  `yaml:"action" json:"action" validate:"omitempty,oneof=off flag fail"`
+	MinLength            *int     `yaml:"minLength" json:"minLength" validate:"omitempty,gte=0"`
+	MaxSymbolRatio       *float64 `yaml:"maxSymbolRatio" json:"maxSymbolRatio" validate:"omitempty,gte=0,lte=1"`
+	MaxRepeatedLineRatio *float64 `yaml:"maxRepeatedLineRatio" json:"maxRepeatedLineRatio" validate:"omitempty,gte=0,lte=1"`
+	MinStopWordRatio     *float64 `yaml:"minStopWordRatio" json:"minStopWordRatio" validate:"omitempty,gte=0,lte=1"`
+	MinThaiRatio         *float64 `yaml:"minThaiRatio" json:"minThaiRatio" validate:"omitempty,gte=0,lte=1"`
+	MinMeanLineLength    *float64 `yaml:"minMeanLineLength" json:"minMeanLineLength" validate:"omitempty,gte=0"`
+}
+
+func (r *Policy) Merge(override *Policy) *Policy {
+	policy := *r
+	if override == nil {
+		return &policy
+	}
+	if override.Action != nil {
+		policy.Action = override.Action
+	}
+	if override.MinLength != nil {
+		policy.MinLength = override.MinLength
+	}
+	if override.MaxSymbolRatio != nil {
+		policy.MaxSymbolRatio = override.MaxSymbolRatio
+	}
+	if override.MaxRepeatedLineRatio != nil {
+		policy.MaxRepeatedLineRatio = override.MaxRepeatedLineRatio
+	}
+	if override.MinStopWordRatio != nil {
+		policy.MinStopWordRatio = override.MinStopWordRatio
+	}
+	if override.MinThaiRatio != nil {
+		policy.MinThaiRatio = override.MinThaiRatio
+	}
+	if override.MinMeanLineLength != nil {
+		policy.MinMeanLineLength = override.MinMeanLineLength
+	}
+	return &policy
+}
+
+func (r *Policy) Evaluate(report *Report) {
+	report.Violations = make([]string, 0)
+
+	// * unset thresholds and unmeasured metrics are not evaluated
+	if r.MinLength != nil && report.Length != nil && *report.Length < *r.MinLength {
+		report.Violations = append(report.Violations, fmt.Sprintf("length %d < %d", *report.Length, *r.MinLength))
+	}
+	if r.MaxSymbolRatio != nil && report.SymbolRatio != nil && *report.SymbolRatio > *r.MaxSymbolRatio {
+		report.Violations = append(report.Violations, fmt.Sprintf("symbol ratio %.2f > %.2f", *report.SymbolRatio, *r.MaxSymbolRatio))
+	}
+	if r.MaxRepeatedLineRatio != nil && report.RepeatedLineRatio != nil && *report.RepeatedLineRatio > *r.MaxRepeatedLineRatio {
+		report.Violations = append(report.Violations, fmt.Sprintf("repeated line ratio %.2f > %.2f", *report.RepeatedLineRatio, *r.MaxRepeatedLineRatio))
+	}
+	if r.MinStopWordRatio != nil && report.StopWordRatio != nil && report.LatinRatio != nil && *report.LatinRatio >= 0.5 && *report.StopWordRatio < *r.MinStopWordRatio {
+		report.Violations = append(report.Violations, fmt.Sprintf("stop word ratio %.2f < %.2f", *report.StopWordRatio, *r.MinStopWordRatio))
+	}
+	if r.MinThaiRatio != nil && report.ThaiRatio != nil && *report.ThaiRatio < *r.MinThaiRatio {
+		report.Violations = append(report.Violations, fmt.Sprintf("thai ratio %.2f < %.2f", *report.ThaiRatio, *r.MinThaiRatio))
+	}
+	if r.MinMeanLineLength != nil && report.MeanLineLength != nil && *report.MeanLineLength < *r.MinMeanLineLength {
+		report.Violations = append(report.Violations, fmt.Sprintf("mean line length %.1f < %.1f", *report.MeanLineLength, *r.MinMeanLineLength))
+	}
+}
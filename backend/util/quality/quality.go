@@ -0,0 +1,102 @@
+package quality
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bsthun/gut"
+)
+
+type Report struct {
+	Length            *int     `json:"length"`
+	SymbolRatio       *float64 `json:"symbolRatio"`
+	RepeatedLineRatio *float64 `json:"repeatedLineRatio"`
+	StopWordRatio     *float64 `json:"stopWordRatio"`
+	ThaiRatio         *float64 `json:"thaiRatio"`
+	LatinRatio        *float64 `json:"latinRatio"`
+	MeanLineLength    *float64 `json:"meanLineLength"`
+	Violations        []string `json:"violations"`
+}
+
+func Measure(text string) *Report {
+	report := &Report{
+		Length:            nil,
+		SymbolRatio:       nil,
+		RepeatedLineRatio: nil,
+		StopWordRatio:     nil,
+		ThaiRatio:         nil,
+		LatinRatio:        nil,
+		MeanLineLength:    nil,
+		Violations:        make([]string, 0),
+	}
+	text = strings.TrimSpace(text)
+	report.Length = gut.Ptr(utf8.RuneCountInString(text))
+
+	// * classify visible characters by script
+	visible := 0
+	symbols := 0
+	thai := 0
+	latin := 0
+	for _, char := range text {
+		if unicode.IsSpace(char) {
+			continue
+		}
+		visible++
+		switch {
+		case unicode.Is(unicode.Thai, char):
+			thai++
+		case unicode.Is(unicode.Latin, char):
+			latin++
+		case unicode.IsLetter(char) || unicode.IsDigit(char) || unicode.IsMark(char):
+		default:
+			symbols++
+		}
+	}
+	if visible > 0 {
+		report.SymbolRatio = gut.Ptr(float64(symbols) / float64(visible))
+	}
+	if thai+latin > 0 {
+		report.ThaiRatio = gut.Ptr(float64(thai) / float64(thai+latin))
+		report.LatinRatio = gut.Ptr(float64(latin) / float64(thai+latin))
+	}
+
+	// * measure non-empty lines and their repetition
+	lines := 0
+	lineLength := 0
+	repeated := 0
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lines++
+		lineLength += utf8.RuneCountInString(line)
+		if seen[line] {
+			repeated++
+		}
+		seen[line] = true
+	}
+	if lines > 0 {
+		report.RepeatedLineRatio = gut.Ptr(float64(repeated) / float64(lines))
+		report.MeanLineLength = gut.Ptr(float64(lineLength) / float64(lines))
+	}
+
+	// * stop words are only measured on latin words, thai text is not space separated
+	words := 0
+	stopWords := 0
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(char rune) bool {
+		return !unicode.Is(unicode.Latin, char) && char != '\''
+	}) {
+		words++
+		if englishStopWords[word] {
+			stopWords++
+		}
+	}
+	if words > 0 {
+		report.StopWordRatio = gut.Ptr(float64(stopWords) / float64(words))
+	}
+
+	return report
+}
@@ -0,0 +1,110 @@
+package quality
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bsthun/gut"
+)
+
+func TestMeasure(t *testing.T) {
+	tests := []struct {
+		name              string
+		text              string
+		length            int
+		symbolRatio       *float64
+		repeatedLineRatio *float64
+		thaiRatio         *float64
+		meanLineLength    *float64
+	}{
+		{name: "empty", text: "  \n ", length: 0, symbolRatio: nil, repeatedLineRatio: nil, thaiRatio: nil, meanLineLength: nil},
+		{name: "symbols", text: "ab!!", length: 4, symbolRatio: gut.Ptr(0.5), repeatedLineRatio: gut.Ptr(0.0), thaiRatio: gut.Ptr(0.0), meanLineLength: gut.Ptr(4.0)},
+		{name: "repeated lines", text: "menu\nmenu\nmenu\nbody", length: 19, symbolRatio: gut.Ptr(0.0), repeatedLineRatio: gut.Ptr(0.5), thaiRatio: gut.Ptr(0.0), meanLineLength: gut.Ptr(4.0)},
+		{name: "mixed script", text: "ไทย abc", length: 7, symbolRatio: gut.Ptr(0.0), repeatedLineRatio: gut.Ptr(0.0), thaiRatio: gut.Ptr(0.5), meanLineLength: gut.Ptr(7.0)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := Measure(test.text)
+			if *report.Length != test.length {
+				t.Errorf("expected length %d, got %d", test.length, *report.Length)
+			}
+			assertRatio(t, "symbol ratio", test.symbolRatio, report.SymbolRatio)
+			assertRatio(t, "repeated line ratio", test.repeatedLineRatio, report.RepeatedLineRatio)
+			assertRatio(t, "thai ratio", test.thaiRatio, report.ThaiRatio)
+			assertRatio(t, "mean line length", test.meanLineLength, report.MeanLineLength)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	english := strings.Repeat("This is a sentence about the weather and it is long enough to read.\n", 3)
+	tests := []struct {
+		name       string
+		policy     *Policy
+		text       string
+		violations []string
+	}{
+		{
+			name:       "unset thresholds",
+			policy:     &Policy{Action: gut.Ptr(ActionFlag)},
+			text:       "!!!",
+			violations: []string{},
+		},
+		{
+			name:       "too short",
+			policy:     &Policy{Action: gut.Ptr(ActionFlag), MinLength: gut.Ptr(10)},
+			text:       "short",
+			violations: []string{"length 5 < 10"},
+		},
+		{
+			name:       "repeated lines",
+			policy:     &Policy{Action: gut.Ptr(ActionFlag), MaxRepeatedLineRatio: gut.Ptr(0.5)},
+			text:       english,
+			violations: []string{"repeated line ratio 0.67 > 0.50"},
+		},
+		{
+			name:       "stop words of latin text",
+			policy:     &Policy{Action: gut.Ptr(ActionFlag), MinStopWordRatio: gut.Ptr(0.9)},
+			text:       "keyword keyword keyword the",
+			violations: []string{"stop word ratio 0.25 < 0.90"},
+		},
+		{
+			name:       "stop words skipped for thai text",
+			policy:     &Policy{Action: gut.Ptr(ActionFlag), MinStopWordRatio: gut.Ptr(0.9)},
+			text:       "ภาษาไทยเป็นภาษาหลัก keyword",
+			violations: []string{},
+		},
+		{
+			name:       "thai ratio",
+			policy:     &Policy{Action: gut.Ptr(ActionFlag), MinThaiRatio: gut.Ptr(0.5)},
+			text:       english,
+			violations: []string{"thai ratio 0.00 < 0.50"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := Measure(test.text)
+			test.policy.Evaluate(report)
+			if !slices.Equal(report.Violations, test.violations) {
+				t.Errorf("expected violations %v, got %v", test.violations, report.Violations)
+			}
+		})
+	}
+}
+
+func assertRatio(t *testing.T, name string, expected *float64, actual *float64) {
+	t.Helper()
+	if expected == nil || actual == nil {
+		if expected != actual {
+			t.Errorf("expected %s %v, got %v", name, expected, actual)
+		}
+		return
+	}
+	if math.Abs(*expected-*actual) > 1e-9 {
+		t.Errorf("expected %s %f, got %f", name, *expected, *actual)
+	}
+}
@@ -0,0 +1,329 @@
+package readability
+
+import (
+	"backend/util/metadata"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type Article struct {
+	Title    string
+	Text     string
+	Metadata *metadata.Metadata
+}
+
+var unlikelyPattern = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|disqus|extra|footer|header|legends|menu|modal|nav|popup|related|remark|share|shoutbox|sidebar|skyscraper|social|sponsor|agegate|pagination|pager`)
+
+var maybePattern = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+
+var positivePattern = regexp.MustCompile(`(?i)article|body|content|entry|hentry|main|page|post|text|blog|story`)
+
+var negativePattern = regexp.MustCompile(`(?i)hidden|banner|combx|comment|contact|foot|footnote|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+
+var removedAtoms = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Iframe:   true,
+	atom.Svg:      true,
+	atom.Canvas:   true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Form:     true,
+	atom.Button:   true,
+	atom.Select:   true,
+	atom.Textarea: true,
+	atom.Input:    true,
+	atom.Nav:      true,
+	atom.Aside:    true,
+	atom.Footer:   true,
+}
+
+var paragraphAtoms = map[atom.Atom]bool{
+	atom.P:          true,
+	atom.H1:         true,
+	atom.H2:         true,
+	atom.H3:         true,
+	atom.H4:         true,
+	atom.H5:         true,
+	atom.H6:         true,
+	atom.Pre:        true,
+	atom.Blockquote: true,
+	atom.Table:      true,
+	atom.Ul:         true,
+	atom.Ol:         true,
+	atom.Dl:         true,
+	atom.Figure:     true,
+	atom.Article:    true,
+	atom.Section:    true,
+	atom.Main:       true,
+	atom.Hr:         true,
+}
+
+var lineAtoms = map[atom.Atom]bool{
+	atom.Div:        true,
+	atom.Li:         true,
+	atom.Tr:         true,
+	atom.Br:         true,
+	atom.Dt:         true,
+	atom.Dd:         true,
+	atom.Figcaption: true,
+	atom.Caption:    true,
+	atom.Address:    true,
+	atom.Header:     true,
+}
+
+func Parse(reader io.Reader) (*Article, error) {
+	doc, err := html.Parse(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// * resolve metadata and title before pruning document
+	meta := metadata.FromHtml(doc)
+	title := ""
+	if meta.Title != nil {
+		title = *meta.Title
+	}
+	if title == "" {
+		if node := find(doc, atom.H1); node != nil {
+			title = innerText(node)
+		}
+	}
+
+	// * strip boilerplate and detect main content
+	prune(doc)
+	nodes := content(doc)
+
+	writer := new(textWriter)
+	for _, node := range nodes {
+		writer.block(2)
+		writer.render(node, false)
+	}
+
+	return &Article{
+		Title:    title,
+		Text:     writer.builder.String(),
+		Metadata: meta,
+	}, nil
+}
+
+func prune(node *html.Node) {
+	for child := node.FirstChild; child != nil; {
+		next := child.NextSibling
+		if removable(child) {
+			node.RemoveChild(child)
+		} else {
+			prune(child)
+		}
+		child = next
+	}
+}
+
+func removable(node *html.Node) bool {
+	switch node.Type {
+	case html.CommentNode:
+		return true
+	case html.ElementNode:
+		if removedAtoms[node.DataAtom] {
+			return true
+		}
+		if hasAttr(node, "hidden") || strings.Contains(strings.ReplaceAll(attr(node, "style"), " ", ""), "display:none") {
+			return true
+		}
+		switch node.DataAtom {
+		case atom.Html, atom.Body, atom.Article, atom.Main:
+			return false
+		}
+		match := attr(node, "class") + " " + attr(node, "id")
+		return unlikelyPattern.MatchString(match) && !maybePattern.MatchString(match)
+	}
+	return false
+}
+
+func content(doc *html.Node) []*html.Node {
+	// * score parents of text paragraphs
+	scores := make(map[*html.Node]float64)
+	walk(doc, func(node *html.Node) {
+		switch node.DataAtom {
+		case atom.P, atom.Pre, atom.Td:
+		default:
+			return
+		}
+		text := innerText(node)
+		if len(text) < 25 {
+			return
+		}
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+		parent := node.Parent
+		if parent == nil || parent.Type != html.ElementNode {
+			return
+		}
+		initialize(scores, parent)
+		scores[parent] += score
+		if grandparent := parent.Parent; grandparent != nil && grandparent.Type == html.ElementNode {
+			initialize(scores, grandparent)
+			scores[grandparent] += score / 2
+		}
+	})
+
+	// * pick top candidate scaled by link density
+	var top *html.Node
+	topScore := 0.0
+	for node, score := range scores {
+		score *= 1 - linkDensity(node)
+		scores[node] = score
+		if top == nil || score > topScore {
+			top = node
+			topScore = score
+		}
+	}
+
+	if top == nil {
+		// * fallback to semantic container or whole body
+		for _, fallback := range []atom.Atom{atom.Article, atom.Main, atom.Body} {
+			if node := find(doc, fallback); node != nil {
+				return []*html.Node{node}
+			}
+		}
+		return []*html.Node{doc}
+	}
+
+	// * include siblings sharing content of top candidate
+	if top.Parent == nil {
+		return []*html.Node{top}
+	}
+	threshold := math.Max(10, topScore*0.2)
+	nodes := make([]*html.Node, 0)
+	for sibling := top.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling.Type != html.ElementNode {
+			continue
+		}
+		if sibling == top {
+			nodes = append(nodes, sibling)
+			continue
+		}
+		if score, ok := scores[sibling]; ok && score >= threshold {
+			nodes = append(nodes, sibling)
+			continue
+		}
+		if sibling.DataAtom == atom.P {
+			text := innerText(sibling)
+			if len(text) > 80 && linkDensity(sibling) < 0.25 {
+				nodes = append(nodes, sibling)
+			}
+		}
+	}
+
+	return nodes
+}
+
+func initialize(scores map[*html.Node]float64, node *html.Node) {
+	if _, ok := scores[node]; ok {
+		return
+	}
+
+	score := 0.0
+	switch node.DataAtom {
+	case atom.Div:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+
+	// * weight by class and id hints
+	for _, hint := range []string{attr(node, "class"), attr(node, "id")} {
+		if hint == "" {
+			continue
+		}
+		if negativePattern.MatchString(hint) {
+			score -= 25
+		}
+		if positivePattern.MatchString(hint) {
+			score += 25
+		}
+	}
+
+	scores[node] = score
+}
+
+func linkDensity(node *html.Node) float64 {
+	length := len(innerText(node))
+	if length == 0 {
+		return 0
+	}
+
+	linkLength := 0
+	walk(node, func(child *html.Node) {
+		if child.DataAtom == atom.A {
+			linkLength += len(innerText(child))
+		}
+	})
+
+	return math.Min(float64(linkLength)/float64(length), 1)
+}
+
+func innerText(node *html.Node) string {
+	builder := new(strings.Builder)
+	var collect func(node *html.Node)
+	collect = func(node *html.Node) {
+		if node.Type == html.TextNode {
+			builder.WriteString(node.Data)
+			builder.WriteByte(' ')
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(node)
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+func walk(node *html.Node, visit func(node *html.Node)) {
+	if node.Type == html.ElementNode {
+		visit(node)
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, visit)
+	}
+}
+
+func find(node *html.Node, target atom.Atom) *html.Node {
+	if node.Type == html.ElementNode && node.DataAtom == target {
+		return node
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if found := find(child, target); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func attr(node *html.Node, key string) string {
+	for _, attribute := range node.Attr {
+		if attribute.Key == key {
+			return attribute.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(node *html.Node, key string) bool {
+	for _, attribute := range node.Attr {
+		if attribute.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package readability
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	paragraph := "This paragraph is long enough to be scored, with commas, clauses, and plenty of words for the content detector."
+	tests := []struct {
+		name     string
+		html     string
+		title    string
+		contains []string
+		excludes []string
+	}{
+		{
+			name: "article with boilerplate",
+			html: `<html><head><title>Document Title</title><meta property="og:title" content="Open Graph Title"></head><body>
+				<nav>Home About Contact</nav>
+				<div class="sidebar">Sidebar links and promos</div>
+				<div class="content"><p>` + paragraph + `</p><p>` + paragraph + `</p></div>
+				<footer>Copyright notice</footer>
+				<script>var tracking = true;</script>
+			</body></html>`,
+			title:    "Open Graph Title",
+			contains: []string{paragraph},
+			excludes: []string{"Home About Contact", "Sidebar links", "Copyright notice", "tracking"},
+		},
+		{
+			name:     "title falls back to heading",
+			html:     `<html><body><article><h1>Heading Title</h1><p>` + paragraph + `</p></article></body></html>`,
+			title:    "Heading Title",
+			contains: []string{paragraph},
+			excludes: nil,
+		},
+		{
+			name:     "hidden and comment nodes",
+			html:     `<html><body><main><p>` + paragraph + `</p><p hidden>hidden paragraph text</p><p style="display: none">invisible paragraph text</p><!-- comment text --></main></body></html>`,
+			title:    "",
+			contains: []string{paragraph},
+			excludes: []string{"hidden paragraph", "invisible paragraph", "comment text"},
+		},
+		{
+			name:     "short body falls back to body",
+			html:     `<html><body><div>Short note</div></body></html>`,
+			title:    "",
+			contains: []string{"Short note"},
+			excludes: nil,
+		},
+		{
+			name:     "list items and preformatted text",
+			html:     `<html><body><div class="post"><p>` + paragraph + `</p><ul><li>first item</li><li>second item</li></ul><pre>keep   spacing</pre></div></body></html>`,
+			title:    "",
+			contains: []string{"- first item\n- second item", "keep   spacing"},
+			excludes: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			article, err := Parse(strings.NewReader(test.html))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if article.Title != test.title {
+				t.Errorf("expected title %q, got %q", test.title, article.Title)
+			}
+			for _, expected := range test.contains {
+				if !strings.Contains(article.Text, expected) {
+					t.Errorf("expected text to contain %q, got %q", expected, article.Text)
+				}
+			}
+			for _, unexpected := range test.excludes {
+				if strings.Contains(article.Text, unexpected) {
+					t.Errorf("expected text to exclude %q, got %q", unexpected, article.Text)
+				}
+			}
+		})
+	}
+}
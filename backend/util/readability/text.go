@@ -0,0 +1,102 @@
+package readability
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type textWriter struct {
+	builder  strings.Builder
+	newlines int
+	space    bool
+}
+
+func (r *textWriter) render(node *html.Node, preserve bool) {
+	switch node.Type {
+	case html.TextNode:
+		r.text(node.Data, preserve)
+		return
+	case html.ElementNode:
+	case html.DocumentNode:
+	default:
+		return
+	}
+
+	// * open block of element
+	if paragraphAtoms[node.DataAtom] {
+		r.block(2)
+	} else if lineAtoms[node.DataAtom] {
+		r.block(1)
+	} else if node.DataAtom == atom.Td || node.DataAtom == atom.Th {
+		r.space = true
+	}
+	if node.DataAtom == atom.Li {
+		r.text("- ", false)
+	}
+	if node.DataAtom == atom.Pre {
+		preserve = true
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		r.render(child, preserve)
+	}
+
+	// * close block of element
+	if paragraphAtoms[node.DataAtom] {
+		r.block(2)
+	} else if lineAtoms[node.DataAtom] {
+		r.block(1)
+	}
+}
+
+func (r *textWriter) text(text string, preserve bool) {
+	// * keep whitespace of preformatted text
+	if preserve {
+		if text == "" {
+			return
+		}
+		r.flush()
+		r.builder.WriteString(text)
+		return
+	}
+
+	// * collapse whitespace of flowing text
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		if text != "" {
+			r.space = true
+		}
+		return
+	}
+	if isSpace(text[0]) {
+		r.space = true
+	}
+	r.flush()
+	r.builder.WriteString(strings.Join(fields, " "))
+	r.space = isSpace(text[len(text)-1])
+}
+
+func (r *textWriter) block(newlines int) {
+	if newlines > r.newlines {
+		r.newlines = newlines
+	}
+}
+
+func (r *textWriter) flush() {
+	// * pending separators are dropped at start of text
+	if r.builder.Len() > 0 {
+		if r.newlines > 0 {
+			r.builder.WriteString(strings.Repeat("\n", r.newlines))
+		} else if r.space {
+			r.builder.WriteByte(' ')
+		}
+	}
+	r.newlines = 0
+	r.space = false
+}
+
+func isSpace(char byte) bool {
+	return char == ' ' || char == '\t' || char == '\n' || char == '\r' || char == '\f'
+}
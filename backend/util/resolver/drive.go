@@ -0,0 +1,52 @@
+package resolver
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+type Drive struct{}
+
+var driveIdPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{10,}$`)
+
+var docsExports = map[string]string{
+	"document":     "export?format=pdf",
+	"presentation": "export/pdf",
+	"spreadsheets": "export?format=csv",
+}
+
+func (r *Drive) Resolve(source *url.URL) (string, bool) {
+	parts := segments(source)
+
+	switch host(source) {
+	case "drive.google.com":
+		// * file id is either in path of file link or in id query of open and uc links
+		id := source.Query().Get("id")
+		for i := 0; i+2 < len(parts); i++ {
+			if parts[i] == "file" && parts[i+1] == "d" {
+				id = parts[i+2]
+				break
+			}
+		}
+		if !driveIdPattern.MatchString(id) {
+			return "", false
+		}
+
+		// * usercontent host skips virus scan confirmation of large files
+		return fmt.Sprintf("https://drive.usercontent.google.com/download?id=%s&export=download&confirm=t", id), true
+	case "docs.google.com":
+		// * google editor documents are exported in extractable format
+		if len(parts) < 3 || parts[1] != "d" || !driveIdPattern.MatchString(parts[2]) {
+			return "", false
+		}
+		export, ok := docsExports[parts[0]]
+		if !ok {
+			return "", false
+		}
+
+		return fmt.Sprintf("https://docs.google.com/%s/d/%s/%s", parts[0], parts[2], export), true
+	}
+
+	return "", false
+}
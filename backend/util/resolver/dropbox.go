@@ -0,0 +1,29 @@
+package resolver
+
+import (
+	"net/url"
+)
+
+type Dropbox struct{}
+
+func (r *Dropbox) Resolve(source *url.URL) (string, bool) {
+	if host(source) != "dropbox.com" {
+		return "", false
+	}
+
+	// * only shared file links are downloadable
+	parts := segments(source)
+	if len(parts) < 2 || (parts[0] != "s" && parts[0] != "scl") {
+		return "", false
+	}
+
+	// * force download while keeping rlkey of new share links
+	resolved := *source
+	query := resolved.Query()
+	query.Del("raw")
+	query.Set("dl", "1")
+	resolved.RawQuery = query.Encode()
+	resolved.Fragment = ""
+
+	return resolved.String(), true
+}
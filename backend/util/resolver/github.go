@@ -0,0 +1,28 @@
+package resolver
+
+import (
+	"net/url"
+	"strings"
+)
+
+type Github struct{}
+
+func (r *Github) Resolve(source *url.URL) (string, bool) {
+	if host(source) != "github.com" {
+		return "", false
+	}
+
+	// * blob and raw links of repository files are served by raw content host
+	parts := segments(source)
+	if len(parts) < 5 || (parts[2] != "blob" && parts[2] != "raw") {
+		return "", false
+	}
+
+	resolved := &url.URL{
+		Scheme: "https",
+		Host:   "raw.githubusercontent.com",
+		Path:   "/" + strings.Join(append(parts[:2:2], parts[3:]...), "/"),
+	}
+
+	return resolved.String(), true
+}
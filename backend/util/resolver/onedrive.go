@@ -0,0 +1,33 @@
+package resolver
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+)
+
+type OneDrive struct{}
+
+func (r *OneDrive) Resolve(source *url.URL) (string, bool) {
+	switch h := host(source); {
+	case h == "1drv.ms" || h == "onedrive.live.com":
+		// * personal share links are downloaded through shares api with encoded sharing url
+		shared := *source
+		shared.Fragment = ""
+		encoded := strings.TrimRight(base64.URLEncoding.EncodeToString([]byte(shared.String())), "=")
+		return "https://api.onedrive.com/v1.0/shares/u!" + encoded + "/root/content", true
+	case strings.HasSuffix(h, ".sharepoint.com"):
+		// * business share links download with download flag
+		if !strings.HasPrefix(source.Path, "/:") {
+			return "", false
+		}
+		resolved := *source
+		query := resolved.Query()
+		query.Set("download", "1")
+		resolved.RawQuery = query.Encode()
+		resolved.Fragment = ""
+		return resolved.String(), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,54 @@
+package resolver
+
+import (
+	"net/url"
+	"strings"
+)
+
+type SourceResolver interface {
+	Resolve(source *url.URL) (string, bool)
+}
+
+var resolvers = []SourceResolver{
+	new(Drive),
+	new(Dropbox),
+	new(OneDrive),
+	new(Github),
+}
+
+func Resolve(source string) (string, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(source))
+	if err != nil || parsed.Host == "" {
+		return source, false
+	}
+
+	// * rewrite share link of first matching resolver to direct download url
+	for _, resolver := range resolvers {
+		if resolved, ok := resolver.Resolve(parsed); ok {
+			return resolved, true
+		}
+	}
+
+	return source, false
+}
+
+func Host(source string) (string, bool) {
+	// * host of direct download url, which is where extraction connects to
+	resolved, _ := Resolve(source)
+	parsed, err := url.Parse(strings.TrimSpace(resolved))
+	if err != nil || parsed.Host == "" {
+		return "", false
+	}
+
+	return host(parsed), true
+}
+
+func host(source *url.URL) string {
+	return strings.TrimPrefix(strings.ToLower(source.Hostname()), "www.")
+}
+
+func segments(source *url.URL) []string {
+	return strings.FieldsFunc(source.Path, func(char rune) bool {
+		return char == '/'
+	})
+}
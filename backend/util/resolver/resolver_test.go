@@ -0,0 +1,66 @@
+package resolver
+
+import "testing"
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+		resolved bool
+	}{
+		{name: "drive file link", source: "https://drive.google.com/file/d/1AbCdEfGhIjK/view?usp=sharing", expected: "https://drive.usercontent.google.com/download?id=1AbCdEfGhIjK&export=download&confirm=t", resolved: true},
+		{name: "drive open link", source: "https://drive.google.com/open?id=1AbCdEfGhIjK", expected: "https://drive.usercontent.google.com/download?id=1AbCdEfGhIjK&export=download&confirm=t", resolved: true},
+		{name: "drive folder link", source: "https://drive.google.com/drive/folders/1AbCdEfGhIjK", expected: "https://drive.google.com/drive/folders/1AbCdEfGhIjK", resolved: false},
+		{name: "docs document", source: "https://docs.google.com/document/d/1AbCdEfGhIjK/edit", expected: "https://docs.google.com/document/d/1AbCdEfGhIjK/export?format=pdf", resolved: true},
+		{name: "docs spreadsheet", source: "https://docs.google.com/spreadsheets/d/1AbCdEfGhIjK/edit#gid=0", expected: "https://docs.google.com/spreadsheets/d/1AbCdEfGhIjK/export?format=csv", resolved: true},
+		{name: "docs unknown editor", source: "https://docs.google.com/forms/d/1AbCdEfGhIjK/edit", expected: "https://docs.google.com/forms/d/1AbCdEfGhIjK/edit", resolved: false},
+		{name: "dropbox share link", source: "https://www.dropbox.com/scl/fi/abc/report.pdf?rlkey=xyz&dl=0", expected: "https://www.dropbox.com/scl/fi/abc/report.pdf?dl=1&rlkey=xyz", resolved: true},
+		{name: "dropbox home", source: "https://www.dropbox.com/home", expected: "https://www.dropbox.com/home", resolved: false},
+		{name: "github blob", source: "https://github.com/owner/repo/blob/main/docs/readme.md", expected: "https://raw.githubusercontent.com/owner/repo/main/docs/readme.md", resolved: true},
+		{name: "github repository", source: "https://github.com/owner/repo", expected: "https://github.com/owner/repo", resolved: false},
+		{name: "onedrive personal", source: "https://1drv.ms/b/s!AbCdEf", expected: "https://api.onedrive.com/v1.0/shares/u!aHR0cHM6Ly8xZHJ2Lm1zL2IvcyFBYkNkRWY/root/content", resolved: true},
+		{name: "sharepoint share", source: "https://contoso.sharepoint.com/:b:/g/abc?e=1", expected: "https://contoso.sharepoint.com/:b:/g/abc?download=1&e=1", resolved: true},
+		{name: "sharepoint page", source: "https://contoso.sharepoint.com/sites/team", expected: "https://contoso.sharepoint.com/sites/team", resolved: false},
+		{name: "other host", source: "https://example.com/file.pdf", expected: "https://example.com/file.pdf", resolved: false},
+		{name: "missing host", source: "file.pdf", expected: "file.pdf", resolved: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resolved, ok := Resolve(test.source)
+			if ok != test.resolved {
+				t.Errorf("expected resolved %v, got %v", test.resolved, ok)
+			}
+			if resolved != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, resolved)
+			}
+		})
+	}
+}
+
+func TestHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+		ok       bool
+	}{
+		{name: "plain url", source: "https://WWW.Example.com/a", expected: "example.com", ok: true},
+		{name: "resolved share link", source: "https://github.com/owner/repo/blob/main/a.md", expected: "raw.githubusercontent.com", ok: true},
+		{name: "drive link", source: "https://drive.google.com/file/d/1AbCdEfGhIjK/view", expected: "drive.usercontent.google.com", ok: true},
+		{name: "missing host", source: "not a url", expected: "", ok: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			host, ok := Host(test.source)
+			if ok != test.ok {
+				t.Errorf("expected ok %v, got %v", test.ok, ok)
+			}
+			if host != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, host)
+			}
+		})
+	}
+}